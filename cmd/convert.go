@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"pkg.fogo.sh/almanac/pkg/content"
+	"pkg.fogo.sh/almanac/pkg/utils"
+)
+
+var convertFrontmatterCmd = &cobra.Command{
+	Use:   "convert-frontmatter",
+	Args:  cobra.NoArgs,
+	Short: "Convert the frontmatter of all pages to TOML or YAML",
+	Long: `Rewrite the frontmatter of every page in the content directory in one format,
+either TOML (between "+++" lines) or YAML (between "---" lines). Fields are
+written in alphabetical order, and comments in converted frontmatter are lost.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		contentDir := must(cmd.Flags().GetString("content-dir"))
+		format := must(cmd.Flags().GetString("to"))
+		dryRun := must(cmd.Flags().GetBool("dry-run"))
+
+		paths, err := filepath.Glob(filepath.Join(contentDir, "*.md"))
+		checkError(err, "failed to find pages")
+
+		converted := 0
+		for _, path := range paths {
+			source, err := os.ReadFile(path)
+			checkError(err, "failed to read page")
+
+			result, changed, err := content.ConvertFrontmatter(source, format)
+			checkError(err, fmt.Sprintf("failed to convert %s", path))
+
+			if !changed {
+				continue
+			}
+
+			converted++
+			slog.Info("Converting page", "path", path)
+
+			if !dryRun {
+				err = utils.WriteFileAtomic(path, result, 0644)
+				checkError(err, "failed to write page")
+			}
+		}
+
+		slog.Info(fmt.Sprintf("converted %d of %d pages to %s", converted, len(paths), format))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(convertFrontmatterCmd)
+
+	convertFrontmatterCmd.Flags().String("to", content.FrontmatterTOML, `Frontmatter format to convert to, "toml" or "yaml"`)
+	convertFrontmatterCmd.Flags().Bool("dry-run", false, "List the pages that would be converted without changing them")
+}
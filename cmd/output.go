@@ -28,7 +28,14 @@ var outputCmd = &cobra.Command{
 			slog.Warn("Failed to create Discord user resolver, Discord user mentions will not be resolved", "error", err)
 		}
 
-		parser := content.Parser{DiscordUserResolver: resolver}
+		parser := content.Parser{
+			DiscordUserResolver: resolver,
+			Dates:               loadDateConfig(),
+			Sitemap:             loadSitemapConfig(),
+			Contributors:        loadContributorConfig(),
+			Categories:          loadCategoryConfig(),
+			IncludeDrafts:       must(cmd.Flags().GetBool("include-drafts")),
+		}
 
 		pages, err := parser.DiscoverPages(contentDir)
 		checkError(err, "failed to discover pages")
@@ -37,7 +44,12 @@ var outputCmd = &cobra.Command{
 
 		slog.Info(fmt.Sprintf("discovered %d pages, outputting to %s", len(pages), outputDir))
 
-		err = parser.OutputAllPagesToDisk(pages, outputDir)
+		baseURL := viper.GetString("base_url")
+		if baseURL == "" {
+			slog.Warn("No base_url configured, links in generated feeds will be relative and no sitemap will be output")
+		}
+
+		err = parser.OutputAllPagesToDisk(pages, outputDir, baseURL)
 		checkError(err, "failed to output pages")
 
 		slog.Info("done!")
@@ -48,4 +60,5 @@ func init() {
 	rootCmd.AddCommand(outputCmd)
 
 	outputCmd.Flags().StringP("output-dir", "o", "./output/", "Directory to output pages to")
+	outputCmd.Flags().Bool("include-drafts", false, "Whether to output drafts and unpublished pages")
 }
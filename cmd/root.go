@@ -64,6 +64,14 @@ func loadConfig() {
 	viper.AddConfigPath(".")
 
 	viper.SetDefault("discord.cache_path", "discord_cache.json")
+	viper.SetDefault("dates.format", "Jan 2, 2006")
+	viper.SetDefault("dates.month_format", "January 2006")
+	viper.SetDefault("dates.year_format", "2006")
+	viper.SetDefault("dates.time_format", "15:04 MST")
+	viper.SetDefault("dates.timezone", "Local")
+	viper.SetDefault("sitemap.exclude_redirects", true)
+	viper.SetDefault("sitemap.exclude_special_pages", true)
+	viper.SetDefault("remote.fetch_interval", "5m")
 
 	if err := viper.ReadInConfig(); err != nil {
 		var configFileNotFoundError viper.ConfigFileNotFoundError
@@ -16,6 +16,15 @@ var serveCmd = &cobra.Command{
 			Addr:             must(cmd.Flags().GetString("addr")),
 			ContentDir:       must(cmd.Flags().GetString("content-dir")),
 			UseBundledAssets: must(cmd.Flags().GetBool("use-bundled-assets")),
+			EnableEditing:    must(cmd.Flags().GetBool("enable-editing")),
+			BaseURL:          viper.GetString("base_url"),
+			Dates:            loadDateConfig(),
+			Sitemap:          loadSitemapConfig(),
+			Contributors:     loadContributorConfig(),
+			Categories:       loadCategoryConfig(),
+			ContentRemote:    loadRemoteConfig(),
+			WebhookSecret:    viper.GetString("remote.webhook_secret"),
+			IncludeDrafts:    must(cmd.Flags().GetBool("include-drafts")),
 
 			UseDiscordOAuth:     must(cmd.Flags().GetBool("use-discord-oauth")),
 			DiscordClientId:     viper.GetString("discord.client_id"),
@@ -37,4 +46,6 @@ func init() {
 	serveCmd.Flags().StringP("addr", "a", ":8080", "Address to listen on")
 	serveCmd.Flags().BoolP("use-bundled-assets", "b", true, "Whether to use bundled assets embedded in the binary")
 	serveCmd.Flags().Bool("use-discord-oauth", false, "Whether to use Discord OAuth for authentication")
+	serveCmd.Flags().Bool("enable-editing", false, "Whether to allow editing pages in the browser, requires Discord OAuth")
+	serveCmd.Flags().Bool("include-drafts", false, "Whether to show drafts and unpublished pages to everyone")
 }
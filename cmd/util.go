@@ -1,8 +1,15 @@
 package cmd
 
 import (
+	"fmt"
 	"log/slog"
 	"os"
+	"time"
+
+	"github.com/spf13/viper"
+
+	"pkg.fogo.sh/almanac/pkg/content"
+	"pkg.fogo.sh/almanac/pkg/history"
 )
 
 func checkError(err error, message string) {
@@ -18,3 +25,51 @@ func must[T any](t T, err error) T {
 	}
 	return t
 }
+
+func loadDateConfig() content.DateConfig {
+	location, err := time.LoadLocation(viper.GetString("dates.timezone"))
+	checkError(err, fmt.Sprintf("invalid timezone %q", viper.GetString("dates.timezone")))
+
+	return content.DateConfig{
+		Format:      viper.GetString("dates.format"),
+		MonthFormat: viper.GetString("dates.month_format"),
+		YearFormat:  viper.GetString("dates.year_format"),
+		TimeFormat:  viper.GetString("dates.time_format"),
+		Location:    location,
+	}
+}
+
+func loadSitemapConfig() content.SitemapConfig {
+	return content.SitemapConfig{
+		ExcludeRedirects:    viper.GetBool("sitemap.exclude_redirects"),
+		ExcludeSpecialPages: viper.GetBool("sitemap.exclude_special_pages"),
+	}
+}
+
+func loadContributorConfig() []content.ContributorConfig {
+	var contributors []content.ContributorConfig
+	err := viper.UnmarshalKey("contributors", &contributors)
+	checkError(err, "invalid contributors config")
+
+	return contributors
+}
+
+func loadRemoteConfig() history.RemoteConfig {
+	return history.RemoteConfig{
+		URL:           viper.GetString("remote.url"),
+		Branch:        viper.GetString("remote.branch"),
+		FetchInterval: viper.GetDuration("remote.fetch_interval"),
+	}
+}
+
+func loadCategoryConfig() map[string]content.CategoryConfig {
+	var categories map[string]content.CategoryConfig
+	err := viper.UnmarshalKey("categories", &categories)
+	checkError(err, "invalid categories config")
+
+	for name, category := range categories {
+		checkError(category.Validate(), fmt.Sprintf("invalid config for category %q", name))
+	}
+
+	return categories
+}
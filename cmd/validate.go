@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"pkg.fogo.sh/almanac/pkg/content"
+)
+
+var validateCmd = &cobra.Command{
+	Use:   "validate",
+	Args:  cobra.NoArgs,
+	Short: "Validate the frontmatter of all pages",
+	Long: `Validate the frontmatter of all pages against the field schemas of their
+categories, and look for misspelled fields. Exits with a non-zero status if any
+problems are found, for use in CI.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		contentDir := must(cmd.Flags().GetString("content-dir"))
+
+		parser := content.Parser{
+			Dates:        loadDateConfig(),
+			Sitemap:      loadSitemapConfig(),
+			Contributors: loadContributorConfig(),
+			Categories:   loadCategoryConfig(),
+			// Drafts are validated too, so they're ready when published.
+			IncludeDrafts: true,
+		}
+
+		// Discovering pages logs each problem found.
+		pages, err := parser.DiscoverPages(contentDir)
+		checkError(err, "failed to discover pages")
+
+		issues := parser.ValidatePages(pages)
+		if len(issues) > 0 {
+			slog.Error(fmt.Sprintf("found %d frontmatter problems", len(issues)))
+			os.Exit(1)
+		}
+
+		slog.Info(fmt.Sprintf("validated %d pages", len(pages)))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(validateCmd)
+}
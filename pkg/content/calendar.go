@@ -0,0 +1,153 @@
+package content
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+const CalendarTitle = "$Calendar"
+
+// CalendarTitleFor returns the title of the calendar feed for a category, or
+// of the whole wiki if category is empty.
+func CalendarTitleFor(category string) string {
+	if category == "" {
+		return CalendarTitle
+	}
+
+	return fmt.Sprintf("%s/%s", CalendarTitle, category)
+}
+
+// PageURL returns the URL of a page, relative to the given base URL.
+func PageURL(baseURL string, title string) string {
+	segments := strings.Split(title, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+
+	return strings.TrimSuffix(baseURL, "/") + "/" + strings.Join(segments, "/")
+}
+
+// Calendar renders every dated page, optionally restricted to a single
+// category, as an iCalendar (RFC 5545) feed.
+func (p *Parser) Calendar(pages map[string]*Page, category string, baseURL string) []byte {
+	var datedPages []*Page
+
+	for _, page := range pages {
+		// Dates only known to the month or year would show up as events
+		// spanning the whole period, which isn't useful in a calendar.
+		if page.Meta.Date == nil || page.Meta.Date.Precision != DatePrecisionDay {
+			continue
+		}
+
+		if category != "" && !page.InCategory(category) {
+			continue
+		}
+
+		datedPages = append(datedPages, page)
+	}
+
+	sort.Slice(datedPages, func(i, j int) bool {
+		if c := CompareDates(datedPages[i].Meta.Date, datedPages[j].Meta.Date); c != 0 {
+			return c < 0
+		}
+		return datedPages[i].Title < datedPages[j].Title
+	})
+
+	name := "Almanac"
+	if category != "" {
+		name = fmt.Sprintf("Almanac: %s", category)
+	}
+
+	var buf bytes.Buffer
+	writeCalendarLine(&buf, "BEGIN", "VCALENDAR")
+	writeCalendarLine(&buf, "VERSION", "2.0")
+	writeCalendarLine(&buf, "PRODID", "-//fogo.sh//Almanac//EN")
+	writeCalendarLine(&buf, "CALSCALE", "GREGORIAN")
+	writeCalendarLine(&buf, "X-WR-CALNAME", escapeCalendarText(name))
+
+	stamp := time.Now().UTC().Format("20060102T150405Z")
+
+	for _, page := range datedPages {
+		pageURL := PageURL(baseURL, page.Title)
+
+		writeCalendarLine(&buf, "BEGIN", "VEVENT")
+		writeCalendarLine(&buf, "UID", fmt.Sprintf("%x@almanac", sha1.Sum([]byte(page.Title))))
+		writeCalendarLine(&buf, "DTSTAMP", stamp)
+		writeCalendarDates(&buf, page.Meta.Date, page.Meta.EndDate)
+		writeCalendarLine(&buf, "SUMMARY", escapeCalendarText(page.Title))
+
+		description := Excerpt(page, 300)
+		if description != "" {
+			description += "\n\n"
+		}
+		writeCalendarLine(&buf, "DESCRIPTION", escapeCalendarText(description+pageURL))
+
+		writeCalendarLine(&buf, "URL", pageURL)
+
+		if len(page.Meta.Categories) > 0 {
+			categories := make([]string, 0, len(page.Meta.Categories))
+			for _, category := range page.Meta.Categories {
+				categories = append(categories, escapeCalendarText(category))
+			}
+			writeCalendarLine(&buf, "CATEGORIES", strings.Join(categories, ","))
+		}
+
+		writeCalendarLine(&buf, "END", "VEVENT")
+	}
+
+	writeCalendarLine(&buf, "END", "VCALENDAR")
+
+	return buf.Bytes()
+}
+
+func writeCalendarDates(buf *bytes.Buffer, start *Date, end *Date) {
+	if start.HasClock {
+		writeCalendarLine(buf, "DTSTART", start.Time.UTC().Format("20060102T150405Z"))
+		if end != nil {
+			writeCalendarLine(buf, "DTEND", end.End().UTC().Format("20060102T150405Z"))
+		}
+		return
+	}
+
+	endDate := start.End()
+	if end != nil && end.End().After(endDate) {
+		endDate = end.End()
+	}
+
+	writeCalendarLine(buf, "DTSTART;VALUE=DATE", start.Start().Format("20060102"))
+	writeCalendarLine(buf, "DTEND;VALUE=DATE", endDate.Format("20060102"))
+}
+
+func escapeCalendarText(value string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+	).Replace(value)
+}
+
+// writeCalendarLine writes a content line, folding it so no line is longer
+// than 75 octets as required by RFC 5545.
+func writeCalendarLine(buf *bytes.Buffer, name string, value string) {
+	line := name + ":" + value
+
+	width := 0
+	for _, r := range line {
+		size := len(string(r))
+		if width+size > 75 {
+			buf.WriteString("\r\n ")
+			width = 1
+		}
+		buf.WriteRune(r)
+		width += size
+	}
+
+	buf.WriteString("\r\n")
+}
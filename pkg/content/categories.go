@@ -0,0 +1,244 @@
+package content
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"sort"
+	"strings"
+)
+
+// CategoryPagePrefix is the title prefix of content pages describing a
+// category. A page such as "Category:Events" supplies the description of the
+// Events category, and its own categories are the parents of Events.
+const CategoryPagePrefix = "Category:"
+
+// CategoryConfig configures the pages in a category.
+type CategoryConfig struct {
+	// Template is the markdown source that new pages in the category start
+	// from.
+	Template string `mapstructure:"template"`
+	// Rollup makes the members of the category's subcategories, at any
+	// depth, members of the category too.
+	Rollup bool `mapstructure:"rollup"`
+	// ListingConfig configures how the category's members are listed.
+	ListingConfig `mapstructure:",squash"`
+	// Fields is the schema of the frontmatter of pages in the category.
+	Fields map[string]FieldSchema `mapstructure:"fields"`
+	// Strict reports frontmatter fields of pages in the category that aren't
+	// declared in the schema of any of their categories.
+	Strict bool `mapstructure:"strict"`
+	// Infobox lists the frontmatter fields shown in the infobox of pages in
+	// the category, in order.
+	Infobox []InfoboxField `mapstructure:"infobox"`
+}
+
+// Validate checks the category's listing, field schemas and infobox.
+func (c CategoryConfig) Validate() error {
+	if err := c.ListingConfig.Validate(); err != nil {
+		return err
+	}
+
+	for name, field := range c.Fields {
+		if err := field.Validate(); err != nil {
+			return fmt.Errorf("invalid field %q: %w", name, err)
+		}
+	}
+
+	for _, field := range c.Infobox {
+		if err := field.Validate(); err != nil {
+			return fmt.Errorf("invalid infobox: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// CategoryConfig returns the configuration of a category. Category names are
+// matched case-insensitively, as keys in the config file are lowercased.
+func (p *Parser) CategoryConfig(category string) CategoryConfig {
+	if config, ok := p.Categories[category]; ok {
+		return config
+	}
+
+	for name, config := range p.Categories {
+		if strings.EqualFold(name, category) {
+			return config
+		}
+	}
+
+	return CategoryConfig{}
+}
+
+// DescribedCategory returns the category a content page describes, if it is a
+// category description page.
+func (p *Page) DescribedCategory() (string, bool) {
+	if p.Path == nil {
+		return "", false
+	}
+
+	category, ok := strings.CutPrefix(p.Title, CategoryPagePrefix)
+	return category, ok && category != ""
+}
+
+// IsCategoryDescription reports whether a content page describes a category.
+func (p *Page) IsCategoryDescription() bool {
+	_, ok := p.DescribedCategory()
+	return ok
+}
+
+// ParentCategories returns the categories a category is in, as listed by its
+// description page.
+func ParentCategories(pages map[string]*Page, category string) []string {
+	description, ok := pages[CategoryPagePrefix+category]
+	if !ok || description.Path == nil {
+		return nil
+	}
+
+	return description.Meta.Categories
+}
+
+// Subcategories returns the categories whose description pages place them in
+// a category, sorted by name.
+func Subcategories(pages map[string]*Page, category string) []string {
+	subcategories := make([]string, 0)
+
+	for _, page := range pages {
+		subcategory, ok := page.DescribedCategory()
+		if !ok {
+			continue
+		}
+
+		for _, parent := range page.Meta.Categories {
+			if parent == category {
+				subcategories = append(subcategories, subcategory)
+				break
+			}
+		}
+	}
+
+	sort.Strings(subcategories)
+
+	return subcategories
+}
+
+// AncestorCategories returns the parents of a category, their parents, and so
+// on, nearest first. Cycles between categories are ignored.
+func AncestorCategories(pages map[string]*Page, category string) []string {
+	ancestors := make([]string, 0)
+	seen := map[string]bool{category: true}
+
+	queue := []string{category}
+	for len(queue) > 0 {
+		for _, parent := range ParentCategories(pages, queue[0]) {
+			if seen[parent] {
+				continue
+			}
+			seen[parent] = true
+			ancestors = append(ancestors, parent)
+			queue = append(queue, parent)
+		}
+		queue = queue[1:]
+	}
+
+	return ancestors
+}
+
+// applyCategoryRollup makes pages members of the ancestors of their
+// categories that are configured to roll up their subcategories.
+func (p *Parser) applyCategoryRollup(pages map[string]*Page) {
+	if len(p.Categories) == 0 {
+		return
+	}
+
+	for _, page := range pages {
+		if page.Path == nil || page.IsCategoryDescription() {
+			continue
+		}
+
+		member := make(map[string]bool)
+		for _, category := range page.Meta.Categories {
+			member[category] = true
+		}
+
+		for _, category := range page.Meta.Categories {
+			for _, ancestor := range AncestorCategories(pages, category) {
+				if member[ancestor] || !p.CategoryConfig(ancestor).Rollup {
+					continue
+				}
+				member[ancestor] = true
+				page.InheritedCategories = append(page.InheritedCategories, ancestor)
+			}
+		}
+	}
+}
+
+// CategoryPage creates the page for a category, showing its description,
+// subcategories and member pages. Members inherited from a subcategory note
+// which of their categories they're included through.
+func (p *Parser) CategoryPage(pages map[string]*Page, category string, members []*Page) (*Page, error) {
+	data := CategoryData{
+		Category:      category,
+		Subcategories: Subcategories(pages, category),
+		Groups:        make([]CategoryMemberGroup, 0),
+	}
+
+	linksTo := make([]string, 0, len(members))
+
+	if description, ok := pages[CategoryPagePrefix+category]; ok && description.Path != nil {
+		data.Description = template.HTML(string(description.ParsedContent))
+		data.DescriptionTitle = description.Title
+		linksTo = append(linksTo, description.Title)
+	}
+
+	for _, subcategory := range data.Subcategories {
+		linksTo = append(linksTo, CategoryPrefix+subcategory)
+	}
+
+	for _, group := range GroupPages(members, p.ListingFor(pages, category)) {
+		memberGroup := CategoryMemberGroup{Heading: group.Heading}
+
+		for _, page := range group.Pages {
+			memberGroup.Members = append(memberGroup.Members, CategoryMember{
+				Title: page.Title,
+				Via:   inheritedThrough(pages, page, category),
+			})
+			linksTo = append(linksTo, page.Title)
+		}
+
+		data.Groups = append(data.Groups, memberGroup)
+	}
+
+	var buf bytes.Buffer
+	err := CategoryTemplate.Execute(&buf, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute template: %w", err)
+	}
+
+	return &Page{
+		Title:         CategoryPrefix + category,
+		LinksTo:       linksTo,
+		Meta:          PageMeta{Categories: ParentCategories(pages, category)},
+		ParsedContent: buf.Bytes(),
+	}, nil
+}
+
+// inheritedThrough returns the category through which a page is a member of an
+// ancestor category, or an empty string if it's a direct member.
+func inheritedThrough(pages map[string]*Page, page *Page, category string) string {
+	for _, direct := range page.Meta.Categories {
+		if direct == category {
+			return ""
+		}
+	}
+
+	for _, direct := range page.Meta.Categories {
+		for _, ancestor := range AncestorCategories(pages, direct) {
+			if ancestor == category {
+				return direct
+			}
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,167 @@
+package content
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+const (
+	CategoryIntersection = '+'
+	CategoryUnion        = '|'
+	CategoryDifference   = '-'
+	// CategoryUnionAlias can be used for unions in wikilinks, where "|"
+	// separates the target from the label.
+	CategoryUnionAlias = ','
+)
+
+// categoryOperators are the operators between the categories of a query.
+var categoryOperators = string([]rune{CategoryIntersection, CategoryUnion, CategoryDifference, CategoryUnionAlias})
+
+// CategoryQueryTerm is a category combined with the results so far by an
+// operator. The first term of a query has no operator.
+type CategoryQueryTerm struct {
+	Operator rune
+	Category string
+}
+
+// CategoryQuery combines categories with intersections ("Events+Videos"),
+// unions ("Events|Videos", or "Events,Videos" in wikilinks) and differences
+// ("Events-Videos"), evaluated from left to right.
+type CategoryQuery []CategoryQueryTerm
+
+// ParseCategoryQuery parses a category query against the known categories.
+// As category names may themselves contain operators, the longest known
+// category at each point in the query is used.
+func ParseCategoryQuery(query string, categories []string) (CategoryQuery, error) {
+	terms := make(CategoryQuery, 0)
+
+	var operator rune
+	rest := query
+
+	for {
+		category := ""
+		for _, candidate := range categories {
+			if len(candidate) > len(category) && strings.HasPrefix(rest, candidate) {
+				next := rest[len(candidate):]
+				if next == "" || strings.ContainsRune(categoryOperators, rune(next[0])) {
+					category = candidate
+				}
+			}
+		}
+
+		if category == "" {
+			return nil, fmt.Errorf("unknown category in %q at %q", query, rest)
+		}
+
+		terms = append(terms, CategoryQueryTerm{Operator: operator, Category: category})
+		rest = rest[len(category):]
+
+		if rest == "" {
+			return terms, nil
+		}
+
+		operator, rest = rune(rest[0]), rest[1:]
+		if operator == CategoryUnionAlias {
+			operator = CategoryUnion
+		}
+	}
+}
+
+// Pages evaluates the query, returning the matching pages.
+func (q CategoryQuery) Pages(pagesByCategory map[string][]*Page) []*Page {
+	result := make(map[*Page]bool)
+
+	for _, term := range q {
+		members := make(map[*Page]bool)
+		for _, page := range pagesByCategory[term.Category] {
+			members[page] = true
+		}
+
+		switch term.Operator {
+		case 0, CategoryUnion:
+			for page := range members {
+				result[page] = true
+			}
+		case CategoryIntersection:
+			for page := range result {
+				if !members[page] {
+					delete(result, page)
+				}
+			}
+		case CategoryDifference:
+			for page := range members {
+				delete(result, page)
+			}
+		}
+	}
+
+	pages := make([]*Page, 0, len(result))
+	for page := range result {
+		pages = append(pages, page)
+	}
+
+	return pages
+}
+
+// CategoryQueryPage creates the page listing the results of a category query,
+// such as "$Category:Events+Videos".
+func (p *Parser) CategoryQueryPage(pages map[string]*Page, title string) (*Page, error) {
+	queryString, ok := strings.CutPrefix(title, CategoryPrefix)
+	if !ok {
+		return nil, fmt.Errorf("%q is not a category page", title)
+	}
+
+	query, err := ParseCategoryQuery(queryString, p.AllCategories(pages))
+	if err != nil {
+		return nil, err
+	}
+
+	data := CategoryData{Category: queryString}
+	linksTo := make([]string, 0)
+
+	for _, group := range GroupPages(query.Pages(p.PagesByCategory(pages)), ListingConfig{}) {
+		memberGroup := CategoryMemberGroup{Heading: group.Heading}
+		for _, page := range group.Pages {
+			memberGroup.Members = append(memberGroup.Members, CategoryMember{Title: page.Title})
+			linksTo = append(linksTo, page.Title)
+		}
+		data.Groups = append(data.Groups, memberGroup)
+	}
+
+	var buf bytes.Buffer
+	err = CategoryTemplate.Execute(&buf, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute template: %w", err)
+	}
+
+	categories := make([]string, 0, len(query))
+	for _, term := range query {
+		categories = append(categories, term.Category)
+	}
+
+	return &Page{
+		Title:         title,
+		LinksTo:       linksTo,
+		Meta:          PageMeta{Categories: categories},
+		ParsedContent: buf.Bytes(),
+	}, nil
+}
+
+// FindPage looks up a page by title, creating category query pages on demand.
+func (p *Parser) FindPage(pages map[string]*Page, title string) (*Page, bool) {
+	if page, ok := pages[title]; ok {
+		return page, true
+	}
+
+	if !strings.HasPrefix(title, CategoryPrefix) {
+		return nil, false
+	}
+
+	page, err := p.CategoryQueryPage(pages, title)
+	if err != nil {
+		return nil, false
+	}
+
+	return page, true
+}
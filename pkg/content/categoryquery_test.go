@@ -0,0 +1,101 @@
+package content
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+var queryCategories = []string{"Events", "Videos", "Sci-Fi", "Sci"}
+
+func TestParseCategoryQuery(t *testing.T) {
+	tests := []struct {
+		query string
+		want  CategoryQuery
+	}{
+		{
+			query: "Events+Videos",
+			want:  CategoryQuery{{Category: "Events"}, {Operator: CategoryIntersection, Category: "Videos"}},
+		},
+		{
+			query: "Events|Videos",
+			want:  CategoryQuery{{Category: "Events"}, {Operator: CategoryUnion, Category: "Videos"}},
+		},
+		{
+			query: "Events,Videos",
+			want:  CategoryQuery{{Category: "Events"}, {Operator: CategoryUnion, Category: "Videos"}},
+		},
+		{
+			query: "Events-Videos",
+			want:  CategoryQuery{{Category: "Events"}, {Operator: CategoryDifference, Category: "Videos"}},
+		},
+		{
+			query: "Sci-Fi-Videos",
+			want:  CategoryQuery{{Category: "Sci-Fi"}, {Operator: CategoryDifference, Category: "Videos"}},
+		},
+		{
+			query: "Events+Sci-Fi|Sci",
+			want: CategoryQuery{
+				{Category: "Events"},
+				{Operator: CategoryIntersection, Category: "Sci-Fi"},
+				{Operator: CategoryUnion, Category: "Sci"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		got, err := ParseCategoryQuery(test.query, queryCategories)
+		if err != nil {
+			t.Errorf("ParseCategoryQuery(%q) failed: %v", test.query, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("ParseCategoryQuery(%q) = %v, want %v", test.query, got, test.want)
+		}
+	}
+}
+
+func TestParseCategoryQueryUnknownCategory(t *testing.T) {
+	for _, query := range []string{"Events+Music", "Sci-Music", "Events|"} {
+		if _, err := ParseCategoryQuery(query, queryCategories); err == nil {
+			t.Errorf("ParseCategoryQuery(%q) succeeded, want an error", query)
+		}
+	}
+}
+
+func TestCategoryQueryPages(t *testing.T) {
+	concert := &Page{Title: "Concert"}
+	premiere := &Page{Title: "Premiere"}
+	trailer := &Page{Title: "Trailer"}
+
+	pagesByCategory := map[string][]*Page{
+		"Events": {concert, premiere},
+		"Videos": {premiere, trailer},
+	}
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{query: "Events+Videos", want: []string{"Premiere"}},
+		{query: "Events|Videos", want: []string{"Concert", "Premiere", "Trailer"}},
+		{query: "Events-Videos", want: []string{"Concert"}},
+	}
+
+	for _, test := range tests {
+		query, err := ParseCategoryQuery(test.query, queryCategories)
+		if err != nil {
+			t.Fatalf("ParseCategoryQuery(%q) failed: %v", test.query, err)
+		}
+
+		got := make([]string, 0)
+		for _, page := range query.Pages(pagesByCategory) {
+			got = append(got, page.Title)
+		}
+		sort.Strings(got)
+
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%q matched %v, want %v", test.query, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package content
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"pkg.fogo.sh/almanac/pkg/history"
+)
+
+// ContributorConfig maps the git identities of a contributor to their page in
+// the wiki and their Discord user.
+type ContributorConfig struct {
+	// Identities are the git author emails or names of the contributor.
+	Identities []string `mapstructure:"identities"`
+	Page       string   `mapstructure:"page"`
+	DiscordID  string   `mapstructure:"discord_id"`
+}
+
+type Contributor struct {
+	Name  string
+	Page  string
+	Lines int
+}
+
+type Contributors struct {
+	Authors     []Contributor
+	FirstAuthor Contributor
+	LastEditor  Contributor
+	LastEdited  string
+}
+
+// resolveContributor finds how to display a git author, using the configured
+// contributor mappings.
+func (p *Parser) resolveContributor(name string, email string) Contributor {
+	for _, config := range p.Contributors {
+		for _, identity := range config.Identities {
+			if !strings.EqualFold(identity, email) && identity != name {
+				continue
+			}
+
+			// Contributors are named by their Discord user if known, linking
+			// to their page.
+			contributor := Contributor{Name: name, Page: config.Page}
+			if config.DiscordID != "" && p.DiscordUserResolver != nil {
+				contributor.Name = p.DiscordUserResolver.Resolve(config.DiscordID)
+			} else if config.Page != "" {
+				contributor.Name = config.Page
+			}
+
+			return contributor
+		}
+	}
+
+	return Contributor{Name: name}
+}
+
+// PageContributors computes who contributed to a content page from the git
+// history of the content directory. It returns nil if the page isn't in a git
+// repository or hasn't been committed.
+func (p *Parser) PageContributors(page *Page) (*Contributors, error) {
+	if page.Path == nil {
+		return nil, nil
+	}
+
+	repo, err := history.Open(filepath.Dir(*page.Path))
+	if errors.Is(err, history.ErrNotRepository) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open content repository: %w", err)
+	}
+
+	authorship, err := repo.Authorship(filepath.Base(*page.Path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute authorship: %w", err)
+	}
+
+	if authorship == nil {
+		return nil, nil
+	}
+
+	contributors := &Contributors{
+		Authors:     make([]Contributor, 0, len(authorship.Authors)),
+		FirstAuthor: p.resolveContributor(authorship.FirstChange.Author, authorship.FirstChange.Email),
+		LastEditor:  p.resolveContributor(authorship.LastChange.Author, authorship.LastChange.Email),
+		LastEdited:  p.Dates.FormatTime(authorship.LastChange.Time),
+	}
+
+	for _, author := range authorship.Authors {
+		contributor := p.resolveContributor(author.Author, author.Email)
+		contributor.Lines = author.Lines
+		contributors.Authors = append(contributors.Authors, contributor)
+	}
+
+	return contributors, nil
+}
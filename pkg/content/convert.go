@@ -0,0 +1,214 @@
+package content
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	FrontmatterTOML = "toml"
+	FrontmatterYAML = "yaml"
+)
+
+// frontmatterDelims are the delimiters of each frontmatter format.
+var frontmatterDelims = map[string]byte{
+	FrontmatterTOML: '+',
+	FrontmatterYAML: '-',
+}
+
+// delimLine reports the delimiter of a line consisting of three or more '+'
+// or '-' characters, and how many there are.
+func delimLine(line []byte) (byte, int) {
+	line = bytes.TrimSuffix(bytes.TrimSuffix(line, []byte("\n")), []byte("\r"))
+	if len(line) < 3 || (line[0] != '+' && line[0] != '-') {
+		return 0, 0
+	}
+
+	for _, c := range line[1:] {
+		if c != line[0] {
+			return 0, 0
+		}
+	}
+
+	return line[0], len(line)
+}
+
+// splitFrontmatter splits a page's source into the delimiter of its
+// frontmatter, the frontmatter and the body, reporting false if it has no
+// frontmatter.
+func splitFrontmatter(source []byte) (byte, []byte, []byte, bool) {
+	lines := bytes.SplitAfter(source, []byte("\n"))
+
+	delim, count := delimLine(lines[0])
+	if delim == 0 {
+		return 0, nil, nil, false
+	}
+
+	offset := len(lines[0])
+	for _, line := range lines[1:] {
+		if d, c := delimLine(line); d == delim && c == count {
+			return delim, source[len(lines[0]):offset], source[offset+len(line):], true
+		}
+		offset += len(line)
+	}
+
+	return 0, nil, nil, false
+}
+
+// tomlEncodable prepares frontmatter values for the TOML encoder, which writes
+// local dates and times without an offset only if they're in UTC.
+func tomlEncodable(value interface{}) interface{} {
+	switch v := value.(type) {
+	case time.Time:
+		switch v.Location() {
+		case tomlLocalDate, tomlLocalDatetime, tomlLocalTime:
+			return time.Date(
+				v.Year(), v.Month(), v.Day(), v.Hour(), v.Minute(), v.Second(), v.Nanosecond(),
+				time.UTC,
+			).In(v.Location())
+		}
+	case []interface{}:
+		list := make([]interface{}, 0, len(v))
+		for _, item := range v {
+			list = append(list, tomlEncodable(item))
+		}
+		return list
+	case []map[string]interface{}:
+		list := make([]map[string]interface{}, 0, len(v))
+		for _, item := range v {
+			list = append(list, tomlEncodable(item).(map[string]interface{}))
+		}
+		return list
+	case map[string]interface{}:
+		mapping := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			mapping[key] = tomlEncodable(item)
+		}
+		return mapping
+	}
+	return value
+}
+
+// yamlNode builds the YAML for a frontmatter value, writing dates as
+// timestamps in the form they were written in TOML.
+func yamlNode(value interface{}) (*yaml.Node, error) {
+	switch v := value.(type) {
+	case time.Time:
+		switch v.Location() {
+		case tomlLocalTime:
+			return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: fieldJSON(v).(string)}, nil
+		case tomlLocalDatetime:
+			// YAML only recognises timestamps without a timezone when they're
+			// separated by a space.
+			return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!timestamp", Value: v.Format("2006-01-02 15:04:05.999999999")}, nil
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!timestamp", Value: fieldJSON(v).(string)}, nil
+	case []interface{}:
+		node := &yaml.Node{Kind: yaml.SequenceNode}
+		for _, item := range v {
+			child, err := yamlNode(item)
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, child)
+		}
+		return node, nil
+	case []map[string]interface{}:
+		list := make([]interface{}, 0, len(v))
+		for _, item := range v {
+			list = append(list, item)
+		}
+		return yamlNode(list)
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		node := &yaml.Node{Kind: yaml.MappingNode}
+		for _, key := range keys {
+			child, err := yamlNode(v[key])
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, child)
+		}
+		return node, nil
+	}
+
+	var node yaml.Node
+	if err := node.Encode(value); err != nil {
+		return nil, err
+	}
+
+	// Strings that would be read back as local date-times or times are quoted.
+	if s, ok := value.(string); ok && node.Style == 0 {
+		if _, ok := yamlLocalTime(s, yamlPlainLayouts); ok {
+			node.Style = yaml.DoubleQuotedStyle
+		}
+	}
+
+	return &node, nil
+}
+
+// ConvertFrontmatter rewrites a page's frontmatter in the given format, "toml"
+// or "yaml", keeping its values. Fields are written in alphabetical order and
+// comments aren't kept. It reports false if the page has no frontmatter or
+// already uses the format.
+func ConvertFrontmatter(source []byte, format string) ([]byte, bool, error) {
+	target, ok := frontmatterDelims[format]
+	if !ok {
+		return nil, false, fmt.Errorf("unknown frontmatter format %q, must be %q or %q", format, FrontmatterTOML, FrontmatterYAML)
+	}
+
+	delim, data, body, ok := splitFrontmatter(source)
+	if !ok || delim == target {
+		return source, false, nil
+	}
+
+	var fields map[string]interface{}
+	var err error
+	if delim == frontmatterDelims[FrontmatterTOML] {
+		err = toml.Unmarshal(data, &fields)
+	} else {
+		err = unmarshalYAMLFrontmatter(data, &fields)
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to decode frontmatter: %w", err)
+	}
+
+	var buf bytes.Buffer
+	delimiter := bytes.Repeat([]byte{target}, 3)
+	buf.Write(delimiter)
+	buf.WriteByte('\n')
+
+	switch format {
+	case FrontmatterTOML:
+		encoder := toml.NewEncoder(&buf)
+		encoder.Indent = ""
+		err = encoder.Encode(tomlEncodable(fields))
+	case FrontmatterYAML:
+		var node *yaml.Node
+		node, err = yamlNode(fields)
+		if err == nil && len(node.Content) > 0 {
+			encoder := yaml.NewEncoder(&buf)
+			encoder.SetIndent(2)
+			err = encoder.Encode(node)
+		}
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to encode frontmatter: %w", err)
+	}
+
+	buf.Write(delimiter)
+	buf.WriteByte('\n')
+	buf.Write(body)
+
+	return buf.Bytes(), true, nil
+}
@@ -0,0 +1,74 @@
+package content
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ValidNewPageTitle reports whether a page can be created with the given
+// title, which is used as its file name.
+func ValidNewPageTitle(title string) bool {
+	if title == "" || title != strings.TrimSpace(title) {
+		return false
+	}
+
+	if strings.HasPrefix(title, "$") || strings.HasPrefix(title, ".") {
+		return false
+	}
+
+	return !strings.ContainsFunc(title, func(r rune) bool {
+		return r == '/' || r == '\\' || unicode.IsControl(r)
+	})
+}
+
+// NewPageSource returns the markdown source a new page in a category starts
+// from: the category's template if it has one, or frontmatter placing the
+// page in the category otherwise. Pages outside of a category start empty.
+func (p *Parser) NewPageSource(category string) string {
+	if category == "" {
+		return ""
+	}
+
+	if template := p.CategoryConfig(category).Template; template != "" {
+		return template
+	}
+
+	return fmt.Sprintf("+++\ncategories = [%s]\n+++\n\n", strconv.Quote(category))
+}
+
+// CreatePage creates a page offering to create a missing page, starting from
+// the template of any existing or configured category.
+func (p *Parser) CreatePage(pages map[string]*Page, title string) (*Page, error) {
+	categories := p.AllCategories(pages)
+
+outer:
+	for name := range p.Categories {
+		for _, category := range categories {
+			if strings.EqualFold(name, category) {
+				continue outer
+			}
+		}
+		categories = append(categories, name)
+	}
+
+	sort.Strings(categories)
+
+	var buf bytes.Buffer
+	err := CreateTemplate.Execute(&buf, CreateData{
+		Title:      title,
+		Categories: categories,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute template: %w", err)
+	}
+
+	return &Page{
+		Title:         title,
+		LinksTo:       []string{},
+		ParsedContent: buf.Bytes(),
+	}, nil
+}
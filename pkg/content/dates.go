@@ -0,0 +1,269 @@
+package content
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+type DatePrecision int
+
+const (
+	DatePrecisionDay DatePrecision = iota
+	DatePrecisionMonth
+	DatePrecisionYear
+)
+
+// Date is a point in time read from page frontmatter. It may be approximate,
+// known only to the month or year, and may or may not include a time of day.
+//
+// In frontmatter, dates can be given as TOML dates or date-times, as a bare
+// year (`date = 2023`), or as a string such as "2023", "2023-08",
+// "2023-08-23" or "2023-08-23T18:00:00-02:30", optionally prefixed with
+// "circa", "c." or "~" to mark them as approximate.
+type Date struct {
+	Time      time.Time
+	Precision DatePrecision
+	Circa     bool
+	// HasClock is true if the date includes a time of day.
+	HasClock bool
+
+	// floating dates have no timezone of their own, and are interpreted in
+	// the configured timezone once decoded.
+	floating bool
+}
+
+var dateLayouts = []struct {
+	layout    string
+	precision DatePrecision
+	hasClock  bool
+	floating  bool
+}{
+	{time.RFC3339, DatePrecisionDay, true, false},
+	{"2006-01-02T15:04:05", DatePrecisionDay, true, true},
+	{"2006-01-02T15:04", DatePrecisionDay, true, true},
+	{"2006-01-02", DatePrecisionDay, false, true},
+	{"2006-01", DatePrecisionMonth, false, true},
+	{"2006", DatePrecisionYear, false, true},
+}
+
+var circaPrefixes = []string{"circa", "ca.", "c.", "~"}
+
+// ParseDate parses a date string in any of the formats accepted in frontmatter.
+func ParseDate(value string) (Date, error) {
+	var date Date
+
+	value = strings.TrimSpace(value)
+	for _, prefix := range circaPrefixes {
+		if rest, ok := strings.CutPrefix(strings.ToLower(value), prefix); ok {
+			date.Circa = true
+			value = strings.TrimSpace(value[len(value)-len(rest):])
+			break
+		}
+	}
+
+	for _, layout := range dateLayouts {
+		t, err := time.Parse(layout.layout, value)
+		if err != nil {
+			continue
+		}
+
+		date.Time = t
+		date.Precision = layout.precision
+		date.HasClock = layout.hasClock
+		date.floating = layout.floating
+		return date, nil
+	}
+
+	return Date{}, fmt.Errorf("invalid date %q", value)
+}
+
+func (d *Date) UnmarshalTOML(value interface{}) error {
+	switch v := value.(type) {
+	case time.Time:
+		zone, _ := v.Zone()
+		*d = Date{
+			Time:      v,
+			Precision: DatePrecisionDay,
+			HasClock:  zone != "date-local",
+			floating:  zone == "date-local" || zone == "datetime-local",
+		}
+	case int64:
+		*d = Date{
+			Time:      time.Date(int(v), time.January, 1, 0, 0, 0, 0, time.UTC),
+			Precision: DatePrecisionYear,
+			floating:  true,
+		}
+	case string:
+		date, err := ParseDate(v)
+		if err != nil {
+			return err
+		}
+		*d = date
+	default:
+		return fmt.Errorf("invalid date %v", value)
+	}
+
+	return nil
+}
+
+// ParseLocalDate parses a date like ParseDate, interpreting dates without a
+// timezone in the configured one.
+func (p *Parser) ParseLocalDate(value string) (Date, error) {
+	date, err := ParseDate(value)
+	if err != nil {
+		return Date{}, err
+	}
+
+	date.localize(p.Dates.location())
+	return date, nil
+}
+
+// localize interprets floating dates in the given location, keeping their
+// wall clock time.
+func (d *Date) localize(location *time.Location) {
+	if d == nil {
+		return
+	}
+
+	if d.floating {
+		d.Time = time.Date(
+			d.Time.Year(), d.Time.Month(), d.Time.Day(),
+			d.Time.Hour(), d.Time.Minute(), d.Time.Second(), d.Time.Nanosecond(),
+			location,
+		)
+		d.floating = false
+		return
+	}
+
+	d.Time = d.Time.In(location)
+}
+
+// Start returns the first instant covered by the date.
+func (d Date) Start() time.Time {
+	switch d.Precision {
+	case DatePrecisionYear:
+		return time.Date(d.Time.Year(), time.January, 1, 0, 0, 0, 0, d.Time.Location())
+	case DatePrecisionMonth:
+		return time.Date(d.Time.Year(), d.Time.Month(), 1, 0, 0, 0, 0, d.Time.Location())
+	case DatePrecisionDay:
+		if d.HasClock {
+			return d.Time
+		}
+		return time.Date(d.Time.Year(), d.Time.Month(), d.Time.Day(), 0, 0, 0, 0, d.Time.Location())
+	}
+	return d.Time
+}
+
+// End returns the instant just after the period covered by the date.
+func (d Date) End() time.Time {
+	start := d.Start()
+
+	switch d.Precision {
+	case DatePrecisionYear:
+		return start.AddDate(1, 0, 0)
+	case DatePrecisionMonth:
+		return start.AddDate(0, 1, 0)
+	case DatePrecisionDay:
+		if d.HasClock {
+			return start
+		}
+		return start.AddDate(0, 0, 1)
+	}
+	return start
+}
+
+// CompareDates orders dates chronologically by the start of the period they
+// cover, placing less precise dates before more precise ones that start at the
+// same instant. Missing dates sort after all others.
+func CompareDates(a *Date, b *Date) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return 1
+	case b == nil:
+		return -1
+	}
+
+	aStart, bStart := a.Start(), b.Start()
+	switch {
+	case aStart.Before(bStart):
+		return -1
+	case aStart.After(bStart):
+		return 1
+	case a.Precision > b.Precision:
+		return -1
+	case a.Precision < b.Precision:
+		return 1
+	}
+	return 0
+}
+
+type DateConfig struct {
+	// Format is the Go reference layout used to display dates known to the day.
+	Format string
+	// MonthFormat is the layout used to display dates known to the month.
+	MonthFormat string
+	// YearFormat is the layout used to display dates known to the year.
+	YearFormat string
+	// TimeFormat is the layout appended to dates that include a time of day.
+	TimeFormat string
+	// Location is the timezone dates without an explicit offset are in.
+	Location *time.Location
+}
+
+func (c DateConfig) location() *time.Location {
+	if c.Location == nil {
+		return time.Local
+	}
+	return c.Location
+}
+
+// FormatTime formats an instant, such as a modification time, for display.
+func (c DateConfig) FormatTime(t time.Time) string {
+	return t.In(c.location()).Format(orDefault(c.Format, "Jan 2, 2006") + " " + orDefault(c.TimeFormat, "15:04 MST"))
+}
+
+func orDefault(value string, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
+
+// Format formats the date for display according to its precision.
+func (d Date) Format(config DateConfig) string {
+	var formatted string
+
+	switch d.Precision {
+	case DatePrecisionYear:
+		formatted = d.Time.Format(orDefault(config.YearFormat, "2006"))
+	case DatePrecisionMonth:
+		formatted = d.Time.Format(orDefault(config.MonthFormat, "January 2006"))
+	case DatePrecisionDay:
+		formatted = d.Time.Format(orDefault(config.Format, "Jan 2, 2006"))
+		if d.HasClock {
+			formatted += " " + d.Time.Format(orDefault(config.TimeFormat, "15:04 MST"))
+		}
+	}
+
+	if d.Circa {
+		formatted = "c. " + formatted
+	}
+
+	return formatted
+}
+
+// FormatDate formats the page's date, or date range if it has an end date.
+func (m PageMeta) FormatDate(config DateConfig) string {
+	if m.Date == nil {
+		return ""
+	}
+
+	if m.EndDate == nil {
+		return m.Date.Format(config)
+	}
+
+	return fmt.Sprintf("%s – %s", m.Date.Format(config), m.EndDate.Format(config))
+}
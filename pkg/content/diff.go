@@ -0,0 +1,313 @@
+package content
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+type DiffOperation string
+
+const (
+	DiffEqual  DiffOperation = "equal"
+	DiffInsert DiffOperation = "insert"
+	DiffDelete DiffOperation = "delete"
+)
+
+type DiffSegment struct {
+	Operation DiffOperation
+	Text      string
+}
+
+// Tokens are diffed as runes, one per distinct token. Diffs are returned as
+// strings, so the runes must be valid: the surrogate range is skipped.
+const (
+	surrogateMin  = 0xD800
+	surrogateSize = 0x800
+	maxDiffTokens = utf8.MaxRune + 1 - surrogateSize
+)
+
+func tokenRune(index int) rune {
+	if index >= surrogateMin {
+		index += surrogateSize
+	}
+	return rune(index)
+}
+
+func tokenIndex(r rune) int {
+	if r >= surrogateMin+surrogateSize {
+		r -= surrogateSize
+	}
+	return int(r)
+}
+
+// diffTokens diffs two sequences of tokens, such as lines or words, returning
+// runs of tokens joined back together.
+func diffTokens(a []string, b []string) []DiffSegment {
+	tokenRunes := make(map[string]rune)
+	tokens := make([]string, 0)
+
+	toRunes := func(values []string) []rune {
+		runes := make([]rune, 0, len(values))
+		for _, value := range values {
+			r, ok := tokenRunes[value]
+			if !ok {
+				r = tokenRune(len(tokens))
+				tokenRunes[value] = r
+				tokens = append(tokens, value)
+			}
+			runes = append(runes, r)
+		}
+		return runes
+	}
+
+	aRunes, bRunes := toRunes(a), toRunes(b)
+
+	// Texts with more distinct tokens than there are runes are shown as
+	// entirely replaced.
+	if len(tokens) > maxDiffTokens {
+		return []DiffSegment{
+			{Operation: DiffDelete, Text: strings.Join(a, "")},
+			{Operation: DiffInsert, Text: strings.Join(b, "")},
+		}
+	}
+
+	dmp := diffmatchpatch.New()
+	dmp.DiffTimeout = 0
+	diffs := dmp.DiffMainRunes(aRunes, bRunes, false)
+
+	segments := make([]DiffSegment, 0, len(diffs))
+	for _, diff := range diffs {
+		var text strings.Builder
+		for _, r := range diff.Text {
+			text.WriteString(tokens[tokenIndex(r)])
+		}
+
+		operation := DiffEqual
+		switch diff.Type {
+		case diffmatchpatch.DiffInsert:
+			operation = DiffInsert
+		case diffmatchpatch.DiffDelete:
+			operation = DiffDelete
+		case diffmatchpatch.DiffEqual:
+		}
+
+		segments = append(segments, DiffSegment{Operation: operation, Text: text.String()})
+	}
+
+	return segments
+}
+
+// DiffLines compares two texts line by line, returning one segment per line.
+func DiffLines(a string, b string) []DiffSegment {
+	split := func(text string) []string {
+		if text == "" {
+			return []string{}
+		}
+		return strings.SplitAfter(text, "\n")
+	}
+
+	lines := make([]DiffSegment, 0)
+	for _, segment := range diffTokens(split(a), split(b)) {
+		for _, line := range strings.SplitAfter(segment.Text, "\n") {
+			if line == "" {
+				continue
+			}
+			lines = append(lines, DiffSegment{Operation: segment.Operation, Text: strings.TrimSuffix(line, "\n")})
+		}
+	}
+
+	return lines
+}
+
+var wordPattern = regexp.MustCompile(`\s+|[^\s]+`)
+
+// DiffWords compares two texts word by word.
+func DiffWords(a string, b string) []DiffSegment {
+	return diffTokens(wordPattern.FindAllString(a, -1), wordPattern.FindAllString(b, -1))
+}
+
+type FieldChange struct {
+	Field   string
+	From    string
+	To      string
+	Added   []string
+	Removed []string
+}
+
+// formatField formats a frontmatter field value for display.
+func (p *Parser) formatField(value reflect.Value) string {
+	if value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return ""
+		}
+		value = value.Elem()
+	}
+
+	switch v := value.Interface().(type) {
+	case Date:
+		return v.Format(p.Dates)
+	case []string:
+		return strings.Join(v, ", ")
+	case string:
+		return v
+	}
+
+	if value.IsZero() {
+		return ""
+	}
+
+	return fmt.Sprint(value.Interface())
+}
+
+// FieldChanges lists the frontmatter fields that differ between two versions
+// of a page. List fields, such as categories, report the values added and
+// removed.
+func (p *Parser) FieldChanges(from PageMeta, to PageMeta) []FieldChange {
+	changes := make([]FieldChange, 0)
+
+	fromValue, toValue := reflect.ValueOf(from), reflect.ValueOf(to)
+	metaType := fromValue.Type()
+
+	for i := 0; i < metaType.NumField(); i++ {
+		field := metaType.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("toml"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+
+		if fromList, ok := fromValue.Field(i).Interface().([]string); ok {
+			toList := toValue.Field(i).Interface().([]string)
+			change := FieldChange{
+				Field:   name,
+				Added:   subtractStrings(toList, fromList),
+				Removed: subtractStrings(fromList, toList),
+			}
+			if len(change.Added) > 0 || len(change.Removed) > 0 {
+				changes = append(changes, change)
+			}
+			continue
+		}
+
+		fromFormatted := p.formatField(fromValue.Field(i))
+		toFormatted := p.formatField(toValue.Field(i))
+		if fromFormatted != toFormatted {
+			changes = append(changes, FieldChange{Field: name, From: fromFormatted, To: toFormatted})
+		}
+	}
+
+	return changes
+}
+
+// CustomFieldChanges lists the custom frontmatter fields that differ between
+// two versions of a page, ordered by field. List fields report the values
+// added and removed.
+func CustomFieldChanges(from Fields, to Fields) []FieldChange {
+	changes := make([]FieldChange, 0)
+
+	names := make([]string, 0, len(from)+len(to))
+	for name := range from {
+		names = append(names, name)
+	}
+	for name := range to {
+		if !from.Has(name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		_, fromList := from[name].([]interface{})
+		_, toList := to[name].([]interface{})
+
+		if fromList || toList {
+			change := FieldChange{
+				Field:   name,
+				Added:   subtractStrings(to.Strings(name), from.Strings(name)),
+				Removed: subtractStrings(from.Strings(name), to.Strings(name)),
+			}
+			if len(change.Added) > 0 || len(change.Removed) > 0 {
+				changes = append(changes, change)
+			}
+			continue
+		}
+
+		fromFormatted, toFormatted := from.String(name), to.String(name)
+		if fromFormatted != toFormatted {
+			changes = append(changes, FieldChange{Field: name, From: fromFormatted, To: toFormatted})
+		}
+	}
+
+	return changes
+}
+
+func subtractStrings(values []string, remove []string) []string {
+	result := make([]string, 0)
+
+outer:
+	for _, value := range values {
+		for _, r := range remove {
+			if value == r {
+				continue outer
+			}
+		}
+		result = append(result, value)
+	}
+
+	return result
+}
+
+// DiffSide is one of the two versions of a page being compared.
+type DiffSide struct {
+	Label  string
+	Link   string
+	Source []byte
+}
+
+// DiffTitleFor returns the title of the page comparing revisions of a page.
+func DiffTitleFor(title string) string {
+	return title + "/diff"
+}
+
+// DiffPage creates a page comparing two versions of a content page: their
+// markdown source line by line, their rendered text word by word, and their
+// frontmatter fields.
+func (p *Parser) DiffPage(page *Page, from DiffSide, to DiffSide, index PageIndex) (*Page, error) {
+	path := filepath.Base(*page.Path)
+
+	fromPage, err := p.ParsePage(path, from.Source, index)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", from.Label, err)
+	}
+
+	toPage, err := p.ParsePage(path, to.Source, index)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", to.Label, err)
+	}
+
+	var buf bytes.Buffer
+	err = DiffTemplate.Execute(&buf, DiffData{
+		Title:        page.Title,
+		From:         from,
+		To:           to,
+		FieldChanges: append(p.FieldChanges(fromPage.Meta, toPage.Meta), CustomFieldChanges(fromPage.Fields, toPage.Fields)...),
+		SourceDiff:   DiffLines(string(from.Source), string(to.Source)),
+		TextDiff:     DiffWords(PlainText(fromPage.ParsedContent), PlainText(toPage.ParsedContent)),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute template: %w", err)
+	}
+
+	return &Page{
+		Title:         DiffTitleFor(page.Title),
+		LinksTo:       []string{page.Title},
+		ParsedContent: buf.Bytes(),
+	}, nil
+}
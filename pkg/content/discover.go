@@ -2,12 +2,19 @@ package content
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
+	"log/slog"
+	"os"
 	"path/filepath"
 	"sort"
 	"strings"
+
+	"pkg.fogo.sh/almanac/pkg/history"
 )
 
+const CategoryPrefix = "$Category:"
+
 func (p *Parser) CreateSpecialPages(pages map[string]*Page) error {
 	specialPages := make([]string, 0)
 
@@ -15,32 +22,53 @@ func (p *Parser) CreateSpecialPages(pages map[string]*Page) error {
 	allCategories := p.AllCategories(pages)
 
 	for _, category := range allCategories {
-		keysOfPagesInCategory := make([]string, 0, len(pagesByCategory[category]))
-		for _, page := range pagesByCategory[category] {
-			keysOfPagesInCategory = append(keysOfPagesInCategory, page.Title)
-		}
-
-		var buf bytes.Buffer
-		err := LinkListingTemplate.Execute(&buf, LinkListingData{
-			LinkList: keysOfPagesInCategory,
-		})
+		page, err := p.CategoryPage(pages, category, pagesByCategory[category])
 		if err != nil {
-			return fmt.Errorf("failed to execute template: %w", err)
+			return fmt.Errorf("failed to create %s page: %w", CategoryPrefix+category, err)
 		}
 
-		pageTitle := fmt.Sprintf("$Category:%s", category)
-		page := &Page{
-			Title:         pageTitle,
-			LinksTo:       keysOfPagesInCategory,
-			ParsedContent: buf.Bytes(),
-		}
+		pages[page.Title] = page
+		specialPages = append(specialPages, page.Title)
+	}
+
+	now := p.Now()
+	onThisDay, err := p.OnThisDayPage(pages, OnThisDayTitle, now.Month(), now.Day(), now)
+	if err != nil {
+		return fmt.Errorf("failed to create %s page: %w", OnThisDayTitle, err)
+	}
+
+	pages[OnThisDayTitle] = onThisDay
+	specialPages = append(specialPages, OnThisDayTitle)
+
+	recentChanges, err := p.RecentChangesPage(pages, RecentChangesFilter{}, false)
+	if err != nil {
+		return fmt.Errorf("failed to create %s page: %w", RecentChangesTitle, err)
+	}
+
+	pages[RecentChangesTitle] = recentChanges
+	specialPages = append(specialPages, RecentChangesTitle)
+
+	pages[RandomTitle] = RandomPlaceholderPage()
+	specialPages = append(specialPages, RandomTitle)
 
-		pages[pageTitle] = page
-		specialPages = append(specialPages, pageTitle)
+	categories, err := p.CategoriesPage(pages)
+	if err != nil {
+		return fmt.Errorf("failed to create %s page: %w", CategoriesTitle, err)
+	}
+
+	pages[CategoriesTitle] = categories
+	specialPages = append(specialPages, CategoriesTitle)
+
+	allPages, err := p.AllPagesPage(pages, 1)
+	if err != nil {
+		return fmt.Errorf("failed to create %s page: %w", AllPagesTitle, err)
 	}
 
+	pages[AllPagesTitle] = allPages
+	specialPages = append(specialPages, AllPagesTitle)
+
 	var buf bytes.Buffer
-	err := LinkListingTemplate.Execute(&buf, LinkListingData{
+	err = LinkListingTemplate.Execute(&buf, LinkListingData{
 		LinkList: specialPages,
 	})
 	if err != nil {
@@ -65,10 +93,19 @@ func (p *Parser) DiscoverPages(path string) (map[string]*Page, error) {
 		return nil, fmt.Errorf("failed to glob files: %w", error)
 	}
 
+	titles := make(map[string]bool)
+	for _, path := range paths {
+		titles[strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))] = true
+	}
+
+	index := func(title string) bool {
+		return titles[title] || strings.HasPrefix(title, "$")
+	}
+
 	pages := make(map[string]*Page)
 
 	for _, path := range paths {
-		page, error := p.ParsePageFile(path)
+		page, error := p.ParsePageFile(path, index)
 		if error != nil {
 			return nil, fmt.Errorf("failed to parse page: %w", error)
 		}
@@ -76,16 +113,69 @@ func (p *Parser) DiscoverPages(path string) (map[string]*Page, error) {
 		pages[page.Title] = &page
 	}
 
-	err := p.CreateSpecialPages(pages)
+	err := p.applyVisibility(pages, titles, index)
+	if err != nil {
+		return nil, err
+	}
+
+	p.applyHistory(path, pages)
+	p.applyCategoryRollup(pages)
+	p.applyInfoboxes(pages, index)
+
+	err = p.CreateSpecialPages(pages)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create special pages: %w", err)
 	}
 
 	p.PopulateBacklinks(pages)
 
+	reportIssues(p.ValidatePages(pages))
+
 	return pages, nil
 }
 
+// applyHistory uses the git history of the content directory, if it is in a
+// repository, as the modification time of each page. Pages with uncommitted
+// changes, such as those saved in the editor, keep their file's modification
+// time.
+func (p *Parser) applyHistory(path string, pages map[string]*Page) {
+	repo, err := history.Open(path)
+	if errors.Is(err, history.ErrNotRepository) {
+		return
+	}
+	if err != nil {
+		slog.Warn("Failed to open content repository, using file modification times", "error", err)
+		return
+	}
+
+	lastChanges, err := repo.LastChanges()
+	if err != nil {
+		slog.Warn("Failed to read content history, using file modification times", "error", err)
+		return
+	}
+
+	for _, page := range pages {
+		if page.Path == nil {
+			continue
+		}
+
+		change, ok := lastChanges[filepath.Base(*page.Path)]
+		if !ok {
+			continue
+		}
+
+		page.HasHistory = true
+
+		source, err := os.ReadFile(*page.Path)
+		if err != nil || !change.Matches(source) {
+			continue
+		}
+
+		page.ModTime = change.Time
+		page.LastChange = &change
+	}
+}
+
 func (p *Parser) PopulateBacklinks(pages map[string]*Page) {
 	for _, page := range pages {
 		for _, link := range page.LinksTo {
@@ -113,6 +203,16 @@ func (p *Parser) PopulateBacklinks(pages map[string]*Page) {
 	}
 }
 
+// lessFold orders strings case-insensitively, breaking ties between strings
+// differing only in case so that the order is deterministic.
+func lessFold(a string, b string) bool {
+	aLower, bLower := strings.ToLower(a), strings.ToLower(b)
+	if aLower != bLower {
+		return aLower < bLower
+	}
+	return a < b
+}
+
 func (p *Parser) AllPageTitles(pages map[string]*Page) []string {
 	allPageTitles := make([]string, 0, len(pages))
 	for key := range pages {
@@ -130,17 +230,31 @@ func (p *Parser) PagesByCategory(pages map[string]*Page) map[string][]*Page {
 	pagesByCategory := make(map[string][]*Page)
 
 	for _, page := range pages {
-		for _, category := range page.Meta.Categories {
+		if page.Path == nil || page.IsCategoryDescription() {
+			continue
+		}
+
+		for _, category := range page.Categories() {
 			pagesByCategory[category] = append(pagesByCategory[category], page)
 		}
 	}
 
+	for _, categoryPages := range pagesByCategory {
+		sort.Slice(categoryPages, func(i, j int) bool {
+			return lessFold(categoryPages[i].Title, categoryPages[j].Title)
+		})
+	}
+
 	return pagesByCategory
 }
 
 func (p *Parser) AllCategories(pages map[string]*Page) []string {
 	categories := map[string]struct{}{}
 	for _, page := range pages {
+		if category, ok := page.DescribedCategory(); ok {
+			categories[category] = struct{}{}
+		}
+
 		for _, category := range page.Meta.Categories {
 			categories[category] = struct{}{}
 		}
@@ -151,6 +265,10 @@ func (p *Parser) AllCategories(pages map[string]*Page) []string {
 		keys = append(keys, k)
 	}
 
+	sort.Slice(keys, func(i, j int) bool {
+		return lessFold(keys[i], keys[j])
+	})
+
 	return keys
 }
 
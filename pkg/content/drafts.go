@@ -0,0 +1,88 @@
+package content
+
+import (
+	"bytes"
+	"fmt"
+)
+
+const DraftPreviewTitle = "Preview Drafts"
+
+// HiddenReason explains why a page is hidden from the public: because it's a
+// draft, it's scheduled to be published later, or it has expired. It returns
+// "" for published pages.
+func (p *Parser) HiddenReason(page *Page) string {
+	now := p.Now()
+
+	switch {
+	case page.Meta.Draft:
+		return "it is a draft"
+	case page.Meta.PublishAt != nil && now.Before(page.Meta.PublishAt.Start()):
+		return fmt.Sprintf("it will be published on %s", page.Meta.PublishAt.Format(p.Dates))
+	case page.Meta.ExpireAt != nil && !now.Before(page.Meta.ExpireAt.Start()):
+		return fmt.Sprintf("it expired on %s", page.Meta.ExpireAt.Format(p.Dates))
+	}
+
+	return ""
+}
+
+// applyVisibility handles drafts and scheduled pages. When drafts are
+// included, hidden pages are marked as such; otherwise they're removed from
+// pages and the index, and pages linking to them are parsed again so that the
+// links are rendered as red links.
+func (p *Parser) applyVisibility(pages map[string]*Page, titles map[string]bool, index PageIndex) error {
+	hidden := make(map[string]bool)
+
+	for title, page := range pages {
+		reason := p.HiddenReason(page)
+		if reason == "" {
+			continue
+		}
+
+		if p.IncludeDrafts {
+			page.Hidden = reason
+			continue
+		}
+
+		hidden[title] = true
+		delete(pages, title)
+		delete(titles, title)
+	}
+
+	if len(hidden) == 0 {
+		return nil
+	}
+
+	for title, page := range pages {
+		for _, link := range page.LinksTo {
+			if !hidden[link] {
+				continue
+			}
+
+			reparsed, err := p.ParsePageFile(*page.Path, index)
+			if err != nil {
+				return fmt.Errorf("failed to parse page: %w", err)
+			}
+			pages[title] = &reparsed
+
+			break
+		}
+	}
+
+	return nil
+}
+
+// DraftPreviewPage creates a page with a form to start or end a preview
+// session showing hidden pages.
+func DraftPreviewPage(data DraftPreviewData) (*Page, error) {
+	var buf bytes.Buffer
+	err := DraftPreviewTemplate.Execute(&buf, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute template: %w", err)
+	}
+
+	return &Page{
+		Title:         DraftPreviewTitle,
+		LinksTo:       []string{},
+		ParsedContent: buf.Bytes(),
+	}, nil
+}
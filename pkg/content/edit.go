@@ -0,0 +1,35 @@
+package content
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+)
+
+// EditTitleFor returns the title of the page for editing a page.
+func EditTitleFor(title string) string {
+	return title + "/edit"
+}
+
+// SourceHash identifies a version of a page's source, so that concurrent edits
+// can be detected.
+func SourceHash(source []byte) string {
+	sum := sha256.Sum256(source)
+	return hex.EncodeToString(sum[:])
+}
+
+// EditPage creates a page with a form for editing a page's markdown source.
+func EditPage(data EditData) (*Page, error) {
+	var buf bytes.Buffer
+	err := EditTemplate.Execute(&buf, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute template: %w", err)
+	}
+
+	return &Page{
+		Title:         EditTitleFor(data.Title),
+		LinksTo:       []string{},
+		ParsedContent: buf.Bytes(),
+	}, nil
+}
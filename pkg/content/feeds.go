@@ -0,0 +1,335 @@
+package content
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+const FeedTitle = "$Feed"
+
+const maxFeedEntries = 50
+
+type FeedFormat struct {
+	Extension   string
+	ContentType string
+	Name        string
+}
+
+var FeedFormats = []FeedFormat{
+	{Extension: "rss", ContentType: "application/rss+xml", Name: "RSS"},
+	{Extension: "atom", ContentType: "application/atom+xml", Name: "Atom"},
+	{Extension: "json", ContentType: "application/feed+json", Name: "JSON Feed"},
+}
+
+// FindFeedFormat looks up a feed format by its file extension.
+func FindFeedFormat(extension string) (FeedFormat, bool) {
+	for _, format := range FeedFormats {
+		if format.Extension == extension {
+			return format, true
+		}
+	}
+	return FeedFormat{}, false
+}
+
+// FeedTitleFor returns the title of the feed for a category, or of the whole
+// wiki if category is empty.
+func FeedTitleFor(category string) string {
+	if category == "" {
+		return FeedTitle
+	}
+
+	return fmt.Sprintf("%s/%s", FeedTitle, category)
+}
+
+type FeedLink struct {
+	Title       string
+	ContentType string
+	Href        string
+}
+
+// FeedLinksFor returns the feeds to advertise on a page: the site-wide feeds,
+// and for category pages the feeds of that category.
+func FeedLinksFor(page *Page) []FeedLink {
+	categories := []string{""}
+	if category, ok := strings.CutPrefix(page.Title, CategoryPrefix); ok {
+		categories = append(categories, category)
+	}
+
+	links := make([]FeedLink, 0, len(categories)*len(FeedFormats))
+	for _, category := range categories {
+		name := "Almanac"
+		if category != "" {
+			name = fmt.Sprintf("Almanac: %s", category)
+		}
+
+		for _, format := range FeedFormats {
+			links = append(links, FeedLink{
+				Title:       fmt.Sprintf("%s (%s)", name, format.Name),
+				ContentType: format.ContentType,
+				Href:        "/" + FeedTitleFor(category) + "." + format.Extension,
+			})
+		}
+	}
+
+	return links
+}
+
+type feedEntry struct {
+	page      *Page
+	published time.Time
+	updated   time.Time
+}
+
+// feedEntries returns the most recently changed content pages, optionally
+// restricted to a category, newest first.
+func (p *Parser) feedEntries(pages map[string]*Page, category string) []feedEntry {
+	entries := make([]feedEntry, 0)
+
+	for _, page := range pages {
+		if page.Path == nil || page.Meta.Redirect != nil {
+			continue
+		}
+
+		if category != "" && !page.InCategory(category) {
+			continue
+		}
+
+		published := page.ModTime
+		if page.Meta.Date != nil {
+			published = page.Meta.Date.Start()
+		}
+
+		entries = append(entries, feedEntry{
+			page:      page,
+			published: published,
+			updated:   page.ModTime,
+		})
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if !entries[i].updated.Equal(entries[j].updated) {
+			return entries[i].updated.After(entries[j].updated)
+		}
+		return entries[i].page.Title < entries[j].page.Title
+	})
+
+	if len(entries) > maxFeedEntries {
+		entries = entries[:maxFeedEntries]
+	}
+
+	return entries
+}
+
+// Feed renders the recently changed pages, optionally restricted to a
+// category, as a feed in the given format.
+func (p *Parser) Feed(pages map[string]*Page, category string, format FeedFormat, baseURL string) ([]byte, error) {
+	entries := p.feedEntries(pages, category)
+
+	name := "Almanac"
+	homePage := PageURL(baseURL, "")
+	if category != "" {
+		name = fmt.Sprintf("Almanac: %s", category)
+		homePage = PageURL(baseURL, CategoryPrefix+category)
+	}
+	feedURL := PageURL(baseURL, FeedTitleFor(category)+"."+format.Extension)
+
+	switch format.Extension {
+	case "rss":
+		return rssFeed(entries, name, homePage, baseURL)
+	case "atom":
+		return atomFeed(entries, name, homePage, feedURL, baseURL)
+	case "json":
+		return jsonFeed(entries, name, homePage, feedURL, baseURL)
+	}
+
+	return nil, fmt.Errorf("unknown feed format %q", format.Extension)
+}
+
+type rssCategory struct {
+	Value string `xml:",chardata"`
+}
+
+type rssItem struct {
+	Title       string        `xml:"title"`
+	Link        string        `xml:"link"`
+	GUID        string        `xml:"guid"`
+	PubDate     string        `xml:"pubDate"`
+	Categories  []rssCategory `xml:"category"`
+	Description string        `xml:"description"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rss struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+func rssFeed(entries []feedEntry, name string, homePage string, baseURL string) ([]byte, error) {
+	channel := rssChannel{
+		Title:         name,
+		Link:          homePage,
+		Description:   fmt.Sprintf("Recently changed pages in %s", name),
+		LastBuildDate: time.Now().Format(time.RFC1123Z),
+	}
+
+	for _, entry := range entries {
+		item := rssItem{
+			Title:       entry.page.Title,
+			Link:        PageURL(baseURL, entry.page.Title),
+			GUID:        PageURL(baseURL, entry.page.Title),
+			PubDate:     entry.published.Format(time.RFC1123Z),
+			Description: string(entry.page.ParsedContent),
+		}
+		for _, category := range entry.page.Meta.Categories {
+			item.Categories = append(item.Categories, rssCategory{Value: category})
+		}
+		channel.Items = append(channel.Items, item)
+	}
+
+	return marshalXMLFeed(rss{Version: "2.0", Channel: channel})
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomContent struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+}
+
+type atomEntry struct {
+	ID         string         `xml:"id"`
+	Title      string         `xml:"title"`
+	Link       atomLink       `xml:"link"`
+	Published  string         `xml:"published"`
+	Updated    string         `xml:"updated"`
+	Categories []atomCategory `xml:"category"`
+	Content    atomContent    `xml:"content"`
+}
+
+type atomFeedDocument struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Author  string      `xml:"author>name"`
+	Links   []atomLink  `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+func atomFeed(entries []feedEntry, name string, homePage string, feedURL string, baseURL string) ([]byte, error) {
+	updated := time.Now()
+	if len(entries) > 0 {
+		updated = entries[0].updated
+	}
+
+	feed := atomFeedDocument{
+		ID:      feedURL,
+		Title:   name,
+		Updated: updated.Format(time.RFC3339),
+		Author:  "Almanac",
+		Links: []atomLink{
+			{Href: homePage},
+			{Href: feedURL, Rel: "self"},
+		},
+	}
+
+	for _, entry := range entries {
+		atom := atomEntry{
+			ID:        PageURL(baseURL, entry.page.Title),
+			Title:     entry.page.Title,
+			Link:      atomLink{Href: PageURL(baseURL, entry.page.Title)},
+			Published: entry.published.Format(time.RFC3339),
+			Updated:   entry.updated.Format(time.RFC3339),
+			Content:   atomContent{Type: "html", Value: string(entry.page.ParsedContent)},
+		}
+		for _, category := range entry.page.Meta.Categories {
+			atom.Categories = append(atom.Categories, atomCategory{Term: category})
+		}
+		feed.Entries = append(feed.Entries, atom)
+	}
+
+	return marshalXMLFeed(feed)
+}
+
+func marshalXMLFeed(feed any) ([]byte, error) {
+	data, err := xml.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal feed: %w", err)
+	}
+
+	return append([]byte(xml.Header), data...), nil
+}
+
+// jsonFeedExtension is the JSON Feed extension holding an item's custom
+// frontmatter fields, under the "_almanac" key.
+type jsonFeedExtension struct {
+	Fields map[string]interface{} `json:"fields"`
+}
+
+type jsonFeedItem struct {
+	ID            string            `json:"id"`
+	URL           string            `json:"url"`
+	Title         string            `json:"title"`
+	ContentHTML   string            `json:"content_html"`
+	DatePublished string            `json:"date_published"`
+	DateModified  string            `json:"date_modified"`
+	Tags          []string          `json:"tags,omitempty"`
+	Almanac       jsonFeedExtension `json:"_almanac"`
+}
+
+type jsonFeedDocument struct {
+	Version     string         `json:"version"`
+	Title       string         `json:"title"`
+	HomePageURL string         `json:"home_page_url"`
+	FeedURL     string         `json:"feed_url"`
+	Items       []jsonFeedItem `json:"items"`
+}
+
+func jsonFeed(entries []feedEntry, name string, homePage string, feedURL string, baseURL string) ([]byte, error) {
+	feed := jsonFeedDocument{
+		Version:     "https://jsonfeed.org/version/1.1",
+		Title:       name,
+		HomePageURL: homePage,
+		FeedURL:     feedURL,
+		Items:       make([]jsonFeedItem, 0, len(entries)),
+	}
+
+	for _, entry := range entries {
+		feed.Items = append(feed.Items, jsonFeedItem{
+			ID:            PageURL(baseURL, entry.page.Title),
+			URL:           PageURL(baseURL, entry.page.Title),
+			Title:         entry.page.Title,
+			ContentHTML:   string(entry.page.ParsedContent),
+			DatePublished: entry.published.Format(time.RFC3339),
+			DateModified:  entry.updated.Format(time.RFC3339),
+			Tags:          entry.page.Meta.Categories,
+			Almanac:       jsonFeedExtension{Fields: entry.page.Fields.JSON()},
+		})
+	}
+
+	data, err := json.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal feed: %w", err)
+	}
+
+	return data, nil
+}
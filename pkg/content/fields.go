@@ -0,0 +1,159 @@
+package content
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Fields are a page's custom frontmatter fields, such as "location" or
+// "version": every field not decoded into PageMeta. Values are as decoded from
+// TOML, so are strings, int64s, float64s, bools, time.Times, or lists and
+// tables of them.
+//
+// In templates, fields can be read directly ({{ .Page.Fields.location }}) or
+// through the typed accessors ({{ .Page.Fields.Strings "attendees" }}).
+type Fields map[string]interface{}
+
+// customFields returns the frontmatter fields not decoded into PageMeta.
+func customFields(raw map[string]interface{}) Fields {
+	fields := make(Fields)
+
+outer:
+	for key, value := range raw {
+		for _, builtin := range builtinFields {
+			if key == builtin {
+				continue outer
+			}
+		}
+		fields[key] = value
+	}
+
+	return fields
+}
+
+// Has reports whether the field is set.
+func (f Fields) Has(key string) bool {
+	_, ok := f[key]
+	return ok
+}
+
+// String returns the field formatted as a string, or "" if it isn't set.
+func (f Fields) String(key string) string {
+	value, ok := f[key]
+	if !ok {
+		return ""
+	}
+
+	if s, ok := value.(string); ok {
+		return s
+	}
+
+	return fmt.Sprint(fieldJSON(value))
+}
+
+// Strings returns the items of a list field formatted as strings. A field
+// with a single value is returned as a list of one.
+func (f Fields) Strings(key string) []string {
+	value, ok := f[key]
+	if !ok {
+		return nil
+	}
+
+	list, ok := value.([]interface{})
+	if !ok {
+		return []string{f.String(key)}
+	}
+
+	strs := make([]string, 0, len(list))
+	for i := range list {
+		strs = append(strs, Fields{key: list[i]}.String(key))
+	}
+
+	return strs
+}
+
+// Int returns the field as an integer, reporting false if it isn't set or
+// isn't a whole number.
+func (f Fields) Int(key string) (int64, bool) {
+	switch v := f[key].(type) {
+	case int64:
+		return v, true
+	case float64:
+		return int64(v), v == float64(int64(v))
+	case string:
+		n, err := strconv.ParseInt(v, 10, 64)
+		return n, err == nil
+	}
+	return 0, false
+}
+
+// Float returns the field as a number, reporting false if it isn't set or
+// isn't numeric.
+func (f Fields) Float(key string) (float64, bool) {
+	return fieldNumber(f[key])
+}
+
+// Bool returns the field as a bool, which is false if it isn't set.
+func (f Fields) Bool(key string) bool {
+	b, _ := f[key].(bool)
+	return b
+}
+
+// Date returns the field parsed like the page's own date, or nil if it isn't
+// set or isn't a valid date. Dates without a timezone are in UTC.
+func (f Fields) Date(key string) *Date {
+	value, ok := f[key]
+	if !ok {
+		return nil
+	}
+
+	var date Date
+	if err := date.UnmarshalTOML(value); err != nil {
+		return nil
+	}
+
+	return &date
+}
+
+// JSON returns the fields with TOML dates and times formatted as they were
+// written, for JSON output. It never returns nil, so pages without fields,
+// such as special pages, have empty fields rather than null.
+func (f Fields) JSON() map[string]interface{} {
+	result := make(map[string]interface{}, len(f))
+	for key, value := range f {
+		result[key] = fieldJSON(value)
+	}
+	return result
+}
+
+// fieldJSON converts a frontmatter value for JSON output.
+func fieldJSON(value interface{}) interface{} {
+	switch v := value.(type) {
+	case time.Time:
+		switch zone, _ := v.Zone(); zone {
+		case "date-local":
+			return v.Format("2006-01-02")
+		case "datetime-local":
+			return v.Format("2006-01-02T15:04:05")
+		case "time-local":
+			return v.Format("15:04:05")
+		}
+		return v.Format(time.RFC3339)
+	case []interface{}:
+		list := make([]interface{}, 0, len(v))
+		for _, item := range v {
+			list = append(list, fieldJSON(item))
+		}
+		return list
+	case []map[string]interface{}:
+		list := make([]interface{}, 0, len(v))
+		for _, item := range v {
+			list = append(list, Fields(item).JSON())
+		}
+		return list
+	case map[string]interface{}:
+		return Fields(v).JSON()
+	}
+	return value
+}
@@ -0,0 +1,62 @@
+package content
+
+import "time"
+
+// PageFilter selects content pages by their metadata. Zero values match every
+// page.
+type PageFilter struct {
+	Category string
+	// From and To select pages whose dates overlap the range between them.
+	// Pages without a date don't match if either is set.
+	From time.Time
+	To   time.Time
+	// Redirect selects only redirects if true, or only pages that aren't
+	// redirects if false.
+	Redirect *bool
+}
+
+// Matches reports whether a page matches the filter.
+func (f PageFilter) Matches(page *Page) bool {
+	if f.Category != "" && !page.InCategory(f.Category) {
+		return false
+	}
+
+	if f.Redirect != nil && *f.Redirect != (page.Meta.Redirect != nil) {
+		return false
+	}
+
+	if !f.From.IsZero() || !f.To.IsZero() {
+		if page.Meta.Date == nil {
+			return false
+		}
+
+		start, end := page.Meta.Date.Start(), page.Meta.Date.End()
+		if page.Meta.EndDate != nil {
+			end = page.Meta.EndDate.End()
+		}
+
+		if !f.From.IsZero() && !end.After(f.From) {
+			return false
+		}
+		if !f.To.IsZero() && !start.Before(f.To) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// FilterPages returns the content pages matching a filter, sorted by title.
+func (p *Parser) FilterPages(pages map[string]*Page, filter PageFilter) []*Page {
+	matching := make([]*Page, 0)
+
+	for _, title := range p.AllPageTitles(pages) {
+		page := pages[title]
+		if page.Path == nil || !filter.Matches(page) {
+			continue
+		}
+		matching = append(matching, page)
+	}
+
+	return matching
+}
@@ -0,0 +1,65 @@
+package content
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+
+	"pkg.fogo.sh/almanac/pkg/history"
+)
+
+// HistoryTitleFor returns the title of the history page of a page.
+func HistoryTitleFor(title string) string {
+	return title + "/history"
+}
+
+// RevisionTitleFor returns the title of a historical revision of a page.
+func RevisionTitleFor(title string, hash string) string {
+	return fmt.Sprintf("%s/revisions/%s", title, hash)
+}
+
+// HistoryPage creates a page listing the commits that changed a content page.
+func (p *Parser) HistoryPage(page *Page, changes []history.Change) (*Page, error) {
+	revisions := make([]HistoryRevision, 0, len(changes))
+	for _, change := range changes {
+		revisions = append(revisions, HistoryRevision{
+			Change:    change,
+			Time:      p.Dates.FormatTime(change.Time),
+			SizeDelta: fmt.Sprintf("%+d", change.SizeDelta),
+			Link:      RevisionTitleFor(page.Title, change.Hash),
+		})
+	}
+
+	var buf bytes.Buffer
+	err := HistoryTemplate.Execute(&buf, HistoryData{
+		Title:     page.Title,
+		Path:      filepath.Base(*page.Path),
+		Revisions: revisions,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute template: %w", err)
+	}
+
+	return &Page{
+		Title:         HistoryTitleFor(page.Title),
+		LinksTo:       []string{page.Title},
+		ParsedContent: buf.Bytes(),
+	}, nil
+}
+
+// RevisionPage parses a page as it was after the given change, marking links
+// to pages missing from index.
+func (p *Parser) RevisionPage(page *Page, change history.Change, content []byte, index PageIndex) (*Page, error) {
+	revision, err := p.ParsePage(change.Path, content, index)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse revision %s: %w", change.ShortHash(), err)
+	}
+
+	revision.Title = page.Title
+	revision.Path = nil
+	revision.ModTime = change.Time
+	revision.LastChange = &change
+	revision.Infobox = p.PageInfobox(&revision, index)
+
+	return &revision, nil
+}
@@ -0,0 +1,197 @@
+package content
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+)
+
+const CategoriesTitle = "$Categories"
+
+const AllPagesTitle = "$AllPages"
+
+const allPagesPerPage = 200
+
+const categoryDescriptionLength = 120
+
+// AllPagesTitleFor returns the title of a page of the $AllPages index. The
+// first page is $AllPages itself.
+func AllPagesTitleFor(number int) string {
+	if number <= 1 {
+		return AllPagesTitle
+	}
+	return AllPagesTitle + "/" + strconv.Itoa(number)
+}
+
+// letterAnchor returns the id of the heading for a letter in an index.
+func letterAnchor(heading string) string {
+	if heading == "#" {
+		return "letter-other"
+	}
+	return "letter-" + heading
+}
+
+// categoryTree builds the entries for categories and their subcategories,
+// skipping categories already on the path to avoid cycles.
+func (p *Parser) categoryTree(
+	pages map[string]*Page,
+	pagesByCategory map[string][]*Page,
+	categories []string,
+	path map[string]bool,
+	seen map[string]bool,
+) []CategoryEntry {
+	entries := make([]CategoryEntry, 0, len(categories))
+
+	for _, category := range categories {
+		if path[category] {
+			continue
+		}
+		seen[category] = true
+
+		entry := CategoryEntry{
+			Name:  category,
+			Count: len(pagesByCategory[category]),
+		}
+
+		if description, ok := pages[CategoryPagePrefix+category]; ok && description.Path != nil {
+			entry.Description = Excerpt(description, categoryDescriptionLength)
+		}
+
+		path[category] = true
+		entry.Subcategories = p.categoryTree(pages, pagesByCategory, Subcategories(pages, category), path, seen)
+		delete(path, category)
+
+		entries = append(entries, entry)
+	}
+
+	return entries
+}
+
+// CategoriesPage creates the $Categories page, listing every category with
+// its member count, description and subcategories, grouped alphabetically by
+// top-level category.
+func (p *Parser) CategoriesPage(pages map[string]*Page) (*Page, error) {
+	pagesByCategory := p.PagesByCategory(pages)
+	allCategories := p.AllCategories(pages)
+
+	topLevel := make([]string, 0)
+	for _, category := range allCategories {
+		if len(ParentCategories(pages, category)) == 0 {
+			topLevel = append(topLevel, category)
+		}
+	}
+
+	seen := make(map[string]bool)
+	entries := p.categoryTree(pages, pagesByCategory, topLevel, map[string]bool{}, seen)
+
+	// Categories whose ancestors form a cycle have no top-level ancestor, so
+	// they're listed at the top level too.
+	for _, category := range allCategories {
+		if !seen[category] {
+			entries = append(entries, p.categoryTree(pages, pagesByCategory, []string{category}, map[string]bool{}, seen)...)
+		}
+	}
+
+	groups := make([]CategoryEntryGroup, 0)
+	for _, entry := range entries {
+		heading := listingGroup(&Page{Title: entry.Name}, ListingGroupAlphabetical)
+		if len(groups) == 0 || groups[len(groups)-1].Heading != heading {
+			groups = append(groups, CategoryEntryGroup{Heading: heading})
+		}
+		groups[len(groups)-1].Categories = append(groups[len(groups)-1].Categories, entry)
+	}
+
+	var buf bytes.Buffer
+	err := CategoriesTemplate.Execute(&buf, CategoriesData{Groups: groups})
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute template: %w", err)
+	}
+
+	linksTo := make([]string, 0, len(allCategories))
+	for _, category := range allCategories {
+		linksTo = append(linksTo, CategoryPrefix+category)
+	}
+
+	return &Page{
+		Title:         CategoriesTitle,
+		LinksTo:       linksTo,
+		ParsedContent: buf.Bytes(),
+	}, nil
+}
+
+// allPagesIndex returns the content pages listed in the $AllPages index,
+// sorted by title.
+func (p *Parser) allPagesIndex(pages map[string]*Page) []*Page {
+	return p.FilterPages(pages, PageFilter{})
+}
+
+// AllPagesCount returns the number of pages in the $AllPages index.
+func (p *Parser) AllPagesCount(pages map[string]*Page) int {
+	count := (len(p.allPagesIndex(pages)) + allPagesPerPage - 1) / allPagesPerPage
+	if count == 0 {
+		return 1
+	}
+	return count
+}
+
+// AllPagesPage creates a page of the $AllPages index, an alphabetical listing
+// of every content page with links to jump to each letter.
+func (p *Parser) AllPagesPage(pages map[string]*Page, number int) (*Page, error) {
+	index := p.allPagesIndex(pages)
+	count := p.AllPagesCount(pages)
+
+	if number < 1 || number > count {
+		return nil, fmt.Errorf("page %d of %s doesn't exist", number, AllPagesTitle)
+	}
+
+	data := AllPagesData{
+		Number: number,
+		Count:  count,
+		Groups: make([]AllPagesGroup, 0),
+	}
+
+	if number > 1 {
+		data.Previous = AllPagesTitleFor(number - 1)
+	}
+	if number < count {
+		data.Next = AllPagesTitleFor(number + 1)
+	}
+
+	letters := make(map[string]bool)
+
+	for i, page := range index {
+		heading := listingGroup(page, ListingGroupAlphabetical)
+		pageNumber := i/allPagesPerPage + 1
+
+		if !letters[heading] {
+			letters[heading] = true
+			data.Letters = append(data.Letters, AllPagesLetter{
+				Letter: heading,
+				Href:   "/" + AllPagesTitleFor(pageNumber) + "#" + letterAnchor(heading),
+			})
+		}
+
+		if pageNumber != number {
+			continue
+		}
+
+		if len(data.Groups) == 0 || data.Groups[len(data.Groups)-1].Heading != heading {
+			data.Groups = append(data.Groups, AllPagesGroup{Heading: heading, Anchor: letterAnchor(heading)})
+		}
+		data.Groups[len(data.Groups)-1].Titles = append(data.Groups[len(data.Groups)-1].Titles, page.Title)
+	}
+
+	var buf bytes.Buffer
+	err := AllPagesTemplate.Execute(&buf, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute template: %w", err)
+	}
+
+	// Index entries aren't recorded as links, as every page would otherwise
+	// have a backlink to the index.
+	return &Page{
+		Title:         AllPagesTitleFor(number),
+		LinksTo:       []string{},
+		ParsedContent: buf.Bytes(),
+	}, nil
+}
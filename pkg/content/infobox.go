@@ -0,0 +1,193 @@
+package content
+
+import (
+	"fmt"
+	"html"
+	"html/template"
+	"net/url"
+	"strings"
+	"unicode"
+
+	"github.com/yuin/goldmark/util"
+)
+
+const (
+	InfoboxFormatText      = "text"
+	InfoboxFormatDate      = "date"
+	InfoboxFormatPage      = "page"
+	InfoboxFormatDiscordID = "discord_id"
+	InfoboxFormatURL       = "url"
+)
+
+var infoboxFormats = []string{InfoboxFormatText, InfoboxFormatDate, InfoboxFormatPage, InfoboxFormatDiscordID, InfoboxFormatURL}
+
+// InfoboxField configures a row of a category's infobox.
+type InfoboxField struct {
+	// Field is the frontmatter field shown in the row.
+	Field string `mapstructure:"field"`
+	// Label is the row's heading. Defaults to the field name.
+	Label string `mapstructure:"label"`
+	// Format is how the value is displayed: "text", "date", "page" (a link to
+	// the page), "discord_id" (the user's name) or "url" (a link). Defaults to
+	// the field's type in the schemas of the page's categories, or "text".
+	Format string `mapstructure:"format"`
+}
+
+// Validate checks that the field is set and its format is known.
+func (f InfoboxField) Validate() error {
+	if f.Field == "" {
+		return fmt.Errorf("infobox rows must have a field")
+	}
+
+	switch f.Format {
+	case "", InfoboxFormatText, InfoboxFormatDate, InfoboxFormatPage, InfoboxFormatDiscordID, InfoboxFormatURL:
+		return nil
+	}
+
+	return fmt.Errorf("unknown infobox format %q, must be one of %s", f.Format, strings.Join(infoboxFormats, ", "))
+}
+
+// label returns the row's heading, derived from the field name if unset.
+func (f InfoboxField) label() string {
+	if f.Label != "" {
+		return f.Label
+	}
+
+	label := []rune(strings.ReplaceAll(f.Field, "_", " "))
+	if len(label) > 0 {
+		label[0] = unicode.ToUpper(label[0])
+	}
+	return string(label)
+}
+
+type InfoboxRow struct {
+	Label string
+	Value template.HTML
+}
+
+// Infobox summarises the facts in a page's frontmatter, as configured for one
+// of its categories.
+type Infobox struct {
+	Category string
+	Rows     []InfoboxRow
+}
+
+// infoboxFormat returns how a row's values are displayed, given the field
+// schemas of the page's categories.
+func infoboxFormat(schemas map[string]FieldSchema, field InfoboxField) string {
+	if field.Format != "" {
+		return field.Format
+	}
+
+	switch schemas[field.Field].Type {
+	case FieldTypeDate, FieldTypePage, FieldTypeDiscordID, FieldTypeURL:
+		return schemas[field.Field].Type
+	}
+
+	return InfoboxFormatText
+}
+
+// formatInfoboxValue renders a single frontmatter value for an infobox.
+func (p *Parser) formatInfoboxValue(value interface{}, format string, index PageIndex) template.HTML {
+	text := fmt.Sprint(value)
+
+	switch format {
+	case InfoboxFormatDate:
+		var date Date
+		if err := date.UnmarshalTOML(value); err == nil {
+			date.localize(p.Dates.location())
+			text = date.Format(p.Dates)
+		}
+	case InfoboxFormatPage:
+		href := util.URLEscape([]byte("/"+text), true)
+		if index != nil && !index(text) {
+			return template.HTML(fmt.Sprintf(
+				`<a href="%s" class="new" title="%s (page does not exist)">%s</a>`,
+				href, html.EscapeString(text), html.EscapeString(text),
+			))
+		}
+		return template.HTML(fmt.Sprintf(`<a href="%s">%s</a>`, href, html.EscapeString(text)))
+	case InfoboxFormatDiscordID:
+		for _, contributor := range p.Contributors {
+			if contributor.DiscordID == text && contributor.Page != "" {
+				return p.formatInfoboxValue(contributor.Page, InfoboxFormatPage, index)
+			}
+		}
+		if p.DiscordUserResolver != nil {
+			text = p.DiscordUserResolver.Resolve(text)
+		} else {
+			text = fmt.Sprintf("<@%s>", text)
+		}
+	case InfoboxFormatURL:
+		if u, err := url.Parse(text); err == nil && (u.Scheme == "http" || u.Scheme == "https") {
+			return template.HTML(fmt.Sprintf(`<a href="%s">%s</a>`, html.EscapeString(text), html.EscapeString(text)))
+		}
+	}
+
+	return template.HTML(html.EscapeString(text))
+}
+
+// PageInfobox builds the infobox of a content page from the first of its
+// categories that configures one, returning nil if none do or the page has
+// none of the infobox's fields.
+func (p *Parser) PageInfobox(page *Page, index PageIndex) *Infobox {
+	if page.IsCategoryDescription() {
+		return nil
+	}
+
+	schemas := p.fieldSchemas(page.Categories())
+
+	for _, category := range page.Categories() {
+		config := p.CategoryConfig(category)
+		if len(config.Infobox) == 0 {
+			continue
+		}
+
+		infobox := &Infobox{Category: category}
+
+		for _, field := range config.Infobox {
+			value, ok := page.fields[field.Field]
+			if !ok || value == "" {
+				continue
+			}
+
+			format := infoboxFormat(schemas, field)
+
+			values, ok := value.([]interface{})
+			if !ok {
+				values = []interface{}{value}
+			}
+
+			formatted := make([]string, 0, len(values))
+			for _, v := range values {
+				formatted = append(formatted, string(p.formatInfoboxValue(v, format, index)))
+			}
+
+			infobox.Rows = append(infobox.Rows, InfoboxRow{
+				Label: field.label(),
+				Value: template.HTML(strings.Join(formatted, ", ")),
+			})
+		}
+
+		if len(infobox.Rows) == 0 {
+			return nil
+		}
+
+		return infobox
+	}
+
+	return nil
+}
+
+// applyInfoboxes builds the infobox of every content page.
+func (p *Parser) applyInfoboxes(pages map[string]*Page, index PageIndex) {
+	if len(p.Categories) == 0 {
+		return
+	}
+
+	for _, page := range pages {
+		if page.Path != nil {
+			page.Infobox = p.PageInfobox(page, index)
+		}
+	}
+}
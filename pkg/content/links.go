@@ -2,7 +2,12 @@ package content
 
 import (
 	"bytes"
+	"fmt"
+	"strings"
 
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/util"
 	"go.abhg.dev/goldmark/wikilink"
 )
 
@@ -23,7 +28,90 @@ func (r WikiLinkResolver) ResolveWikilink(node *wikilink.Node) (destination []by
 		err = r.recordDestination(destination)
 	}
 
+	// Links are absolute, so they work from nested pages such as revisions.
+	if len(node.Target) > 0 {
+		destination = append([]byte("/"), destination...)
+	}
+
 	return destination, err
 }
 
 var _ wikilink.Resolver = WikiLinkResolver{}
+
+// PageIndex reports whether a page with the given title exists.
+type PageIndex func(title string) bool
+
+// PageIndexOf returns a PageIndex for a set of pages. Special pages are
+// generated rather than discovered, so they're always considered to exist.
+func PageIndexOf(pages map[string]*Page) PageIndex {
+	return func(title string) bool {
+		if strings.HasPrefix(title, "$") {
+			return true
+		}
+		_, ok := pages[title]
+		return ok
+	}
+}
+
+// wikiLinkRenderer renders wikilinks as wikilink.Renderer does, but marks
+// links to pages missing from the index with the "new" class.
+type wikiLinkRenderer struct {
+	resolver wikilink.Resolver
+	index    PageIndex
+	fallback *wikilink.Renderer
+	hasDest  map[*wikilink.Node]bool
+}
+
+func newWikiLinkRenderer(resolver wikilink.Resolver, index PageIndex) *wikiLinkRenderer {
+	return &wikiLinkRenderer{
+		resolver: resolver,
+		index:    index,
+		fallback: &wikilink.Renderer{Resolver: resolver},
+		hasDest:  make(map[*wikilink.Node]bool),
+	}
+}
+
+func (r *wikiLinkRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(wikilink.Kind, r.render)
+}
+
+func (r *wikiLinkRenderer) render(w util.BufWriter, src []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	n, ok := node.(*wikilink.Node)
+	if !ok {
+		return ast.WalkStop, fmt.Errorf("unexpected node %T, expected *wikilink.Node", node)
+	}
+
+	// Embeds, such as images, are left to the extension's own renderer.
+	if n.Embed {
+		return r.fallback.Render(w, src, node, entering)
+	}
+
+	if !entering {
+		if r.hasDest[n] {
+			delete(r.hasDest, n)
+			_, _ = w.WriteString("</a>")
+		}
+		return ast.WalkContinue, nil
+	}
+
+	dest, err := r.resolver.ResolveWikilink(n)
+	if err != nil {
+		return ast.WalkStop, fmt.Errorf("resolve %q: %w", n.Target, err)
+	}
+	if len(dest) == 0 {
+		return ast.WalkContinue, nil
+	}
+
+	r.hasDest[n] = true
+	_, _ = w.WriteString(`<a href="`)
+	_, _ = w.Write(util.URLEscape(dest, true))
+	_, _ = w.WriteString(`"`)
+	if len(n.Target) > 0 && r.index != nil && !r.index(string(n.Target)) {
+		_, _ = w.WriteString(` class="new" title="`)
+		_, _ = w.Write(util.EscapeHTML(n.Target))
+		_, _ = w.WriteString(` (page does not exist)"`)
+	}
+	_, _ = w.WriteString(`>`)
+
+	return ast.WalkContinue, nil
+}
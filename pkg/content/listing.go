@@ -0,0 +1,254 @@
+package content
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	ListingSortTitle  = "title"
+	ListingSortDate   = "date"
+	ListingSortWeight = "weight"
+
+	ListingOrderAscending  = "asc"
+	ListingOrderDescending = "desc"
+
+	ListingGroupAlphabetical = "alpha"
+	ListingGroupYear         = "year"
+)
+
+// undatedGroup is the heading of pages without a date when grouping by year.
+const undatedGroup = "Undated"
+
+// ListingConfig configures how the members of a category are listed. It can
+// be set for a category in almanac.toml, or in a [listing] table in the
+// frontmatter of the category's description page.
+type ListingConfig struct {
+	// Sort is the key pages are sorted by: "title", "date", "weight", or the
+	// name of any other frontmatter field. Defaults to "title".
+	Sort string `toml:"sort" yaml:"sort" mapstructure:"sort"`
+	// Order is "asc" or "desc". Defaults to "asc".
+	Order string `toml:"order" yaml:"order" mapstructure:"order"`
+	// Group adds headers to the listing, either "alpha" for the first letter
+	// of the title or "year" for the year of the page's date.
+	Group string `toml:"group" yaml:"group" mapstructure:"group"`
+}
+
+// mergedWith returns the config with fields overridden by those set in other.
+func (c ListingConfig) mergedWith(other *ListingConfig) ListingConfig {
+	if other == nil {
+		return c
+	}
+
+	if other.Sort != "" {
+		c.Sort = other.Sort
+	}
+	if other.Order != "" {
+		c.Order = other.Order
+	}
+	if other.Group != "" {
+		c.Group = other.Group
+	}
+
+	return c
+}
+
+// Validate checks that the order and grouping are known values.
+func (c ListingConfig) Validate() error {
+	switch c.Order {
+	case "", ListingOrderAscending, ListingOrderDescending:
+	default:
+		return fmt.Errorf("invalid listing order %q, must be %q or %q", c.Order, ListingOrderAscending, ListingOrderDescending)
+	}
+
+	switch c.Group {
+	case "", ListingGroupAlphabetical, ListingGroupYear:
+	default:
+		return fmt.Errorf("invalid listing group %q, must be %q or %q", c.Group, ListingGroupAlphabetical, ListingGroupYear)
+	}
+
+	return nil
+}
+
+// ListingFor returns how a category's members are listed, from its config
+// overridden by its description page.
+func (p *Parser) ListingFor(pages map[string]*Page, category string) ListingConfig {
+	listing := p.CategoryConfig(category).ListingConfig
+
+	if description, ok := pages[CategoryPagePrefix+category]; ok && description.Path != nil {
+		listing = listing.mergedWith(description.Meta.Listing)
+	}
+
+	return listing
+}
+
+// compareTitles orders titles case-insensitively.
+func compareTitles(a string, b string) int {
+	return strings.Compare(strings.ToLower(a), strings.ToLower(b))
+}
+
+// compareFieldValues orders two frontmatter values of the same kind, reporting
+// false if they can't be compared.
+func compareFieldValues(a interface{}, b interface{}) (int, bool) {
+	if aNumber, ok := fieldNumber(a); ok {
+		if bNumber, ok := fieldNumber(b); ok {
+			switch {
+			case aNumber < bNumber:
+				return -1, true
+			case aNumber > bNumber:
+				return 1, true
+			}
+			return 0, true
+		}
+	}
+
+	if aTime, ok := a.(time.Time); ok {
+		if bTime, ok := b.(time.Time); ok {
+			return aTime.Compare(bTime), true
+		}
+	}
+
+	if aString, ok := a.(string); ok {
+		if bString, ok := b.(string); ok {
+			return compareTitles(aString, bString), true
+		}
+	}
+
+	return 0, false
+}
+
+func fieldNumber(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int64:
+		return float64(v), true
+	case float64:
+		return v, true
+	case string:
+		n, err := strconv.ParseFloat(v, 64)
+		return n, err == nil
+	}
+	return 0, false
+}
+
+// comparePagesBy orders two pages by a sort key. Pages missing the key are
+// reported as such, so they can be placed last regardless of the order.
+func comparePagesBy(a *Page, b *Page, key string) (result int, aMissing bool, bMissing bool) {
+	switch key {
+	case "", ListingSortTitle:
+		return compareTitles(a.Title, b.Title), false, false
+	case ListingSortDate:
+		return CompareDates(a.Meta.Date, b.Meta.Date), a.Meta.Date == nil, b.Meta.Date == nil
+	}
+
+	aValue, aOk := a.fields[key]
+	bValue, bOk := b.fields[key]
+	if !aOk || !bOk {
+		return 0, !aOk, !bOk
+	}
+
+	result, ok := compareFieldValues(aValue, bValue)
+	if !ok {
+		result = strings.Compare(fmt.Sprint(aValue), fmt.Sprint(bValue))
+	}
+
+	return result, false, false
+}
+
+// SortPages sorts pages in place as configured by a listing, falling back to
+// their titles. Pages missing the sort key are placed last.
+func SortPages(pages []*Page, listing ListingConfig) {
+	sort.SliceStable(pages, func(i, j int) bool {
+		result, iMissing, jMissing := comparePagesBy(pages[i], pages[j], listing.Sort)
+
+		if iMissing != jMissing {
+			return jMissing
+		}
+
+		if listing.Order == ListingOrderDescending {
+			result = -result
+		}
+
+		if result == 0 {
+			return lessFold(pages[i].Title, pages[j].Title)
+		}
+
+		return result < 0
+	})
+}
+
+// listingGroup returns the heading a page is listed under.
+func listingGroup(page *Page, group string) string {
+	switch group {
+	case ListingGroupAlphabetical:
+		for _, r := range page.Title {
+			if unicode.IsLetter(r) {
+				return string(unicode.ToUpper(r))
+			}
+			return "#"
+		}
+		return "#"
+	case ListingGroupYear:
+		if page.Meta.Date == nil {
+			return undatedGroup
+		}
+		return strconv.Itoa(page.Meta.Date.Start().Year())
+	}
+	return ""
+}
+
+// compareGroups orders group headings: alphabetically, or by year in the
+// listing's order with undated pages last.
+func compareGroups(a string, b string, listing ListingConfig) bool {
+	if listing.Group == ListingGroupYear {
+		if a == undatedGroup || b == undatedGroup {
+			return b == undatedGroup && a != undatedGroup
+		}
+
+		aYear, _ := strconv.Atoi(a)
+		bYear, _ := strconv.Atoi(b)
+		if listing.Sort == ListingSortDate && listing.Order == ListingOrderDescending {
+			return aYear > bYear
+		}
+		return aYear < bYear
+	}
+
+	return a < b
+}
+
+// GroupPages sorts pages as configured by a listing and splits them under
+// headings. Listings without grouping have a single group with no heading.
+func GroupPages(pages []*Page, listing ListingConfig) []PageGroup {
+	sorted := append([]*Page{}, pages...)
+	SortPages(sorted, listing)
+
+	groups := make([]PageGroup, 0)
+	indexes := make(map[string]int)
+
+	for _, page := range sorted {
+		heading := listingGroup(page, listing.Group)
+
+		i, ok := indexes[heading]
+		if !ok {
+			i = len(groups)
+			indexes[heading] = i
+			groups = append(groups, PageGroup{Heading: heading})
+		}
+
+		groups[i].Pages = append(groups[i].Pages, page)
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		return compareGroups(groups[i].Heading, groups[j].Heading, listing)
+	})
+
+	return groups
+}
+
+type PageGroup struct {
+	Heading string
+	Pages   []*Page
+}
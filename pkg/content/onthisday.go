@@ -0,0 +1,104 @@
+package content
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+const OnThisDayTitle = "$OnThisDay"
+
+// ParseMonthDay parses a "MM-DD" string, as used in `$OnThisDay/MM-DD` page titles.
+func ParseMonthDay(value string) (time.Month, int, error) {
+	// 2024 is a leap year, so Feb 29 parses successfully.
+	t, err := time.Parse("2006-01-02", "2024-"+value)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid month and day %q: %w", value, err)
+	}
+
+	return t.Month(), t.Day(), nil
+}
+
+// OnThisDayTitleFor returns the title of the `$OnThisDay` variant for the given month and day.
+func OnThisDayTitleFor(month time.Month, day int) string {
+	return fmt.Sprintf("%s/%02d-%02d", OnThisDayTitle, int(month), day)
+}
+
+// AllMonthDays returns every calendar day of a leap year, in order.
+func AllMonthDays() []time.Time {
+	days := make([]time.Time, 0, 366)
+	for d := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC); d.Year() == 2024; d = d.AddDate(0, 0, 1) {
+		days = append(days, d)
+	}
+	return days
+}
+
+// OnThisDayPage creates a page listing every dated page that happened on the
+// given month and day in a year before now.
+func (p *Parser) OnThisDayPage(
+	pages map[string]*Page,
+	title string,
+	month time.Month,
+	day int,
+	now time.Time,
+) (*Page, error) {
+	byYear := make(map[int][]string)
+
+	for _, page := range pages {
+		// Dates only known to the month or year can't be placed on a day.
+		if page.Meta.Date == nil || page.Meta.Date.Precision != DatePrecisionDay {
+			continue
+		}
+
+		// Pages from this year aren't anniversaries yet.
+		date := page.Meta.Date.Time
+		if date.Month() != month || date.Day() != day || date.Year() >= now.Year() {
+			continue
+		}
+
+		byYear[date.Year()] = append(byYear[date.Year()], page.Title)
+	}
+
+	years := make([]OnThisDayYear, 0, len(byYear))
+	linksTo := make([]string, 0)
+
+	for year, titles := range byYear {
+		sort.Slice(titles, func(i, j int) bool {
+			return strings.ToLower(titles[i]) < strings.ToLower(titles[j])
+		})
+
+		years = append(years, OnThisDayYear{
+			Year:     year,
+			YearsAgo: now.Year() - year,
+			LinkList: titles,
+		})
+		linksTo = append(linksTo, titles...)
+	}
+
+	sort.Slice(years, func(i, j int) bool {
+		return years[i].Year > years[j].Year
+	})
+
+	date := time.Date(2024, month, day, 0, 0, 0, 0, time.UTC)
+	previous := date.AddDate(0, 0, -1)
+	next := date.AddDate(0, 0, 1)
+
+	var buf bytes.Buffer
+	err := OnThisDayTemplate.Execute(&buf, OnThisDayData{
+		Day:      date.Format("January 2"),
+		Years:    years,
+		Previous: OnThisDayTitleFor(previous.Month(), previous.Day()),
+		Next:     OnThisDayTitleFor(next.Month(), next.Day()),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute template: %w", err)
+	}
+
+	return &Page{
+		Title:         title,
+		LinksTo:       linksTo,
+		ParsedContent: buf.Bytes(),
+	}, nil
+}
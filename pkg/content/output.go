@@ -2,14 +2,20 @@ package content
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
 	"html/template"
+	"log/slog"
 	"os"
+	"path/filepath"
+	"strings"
 
 	cp "github.com/otiai10/copy"
+
+	"pkg.fogo.sh/almanac/pkg/utils"
 )
 
-func (p *Parser) OutputAllPagesToDisk(pages map[string]*Page, outputDir string) error {
+func (p *Parser) OutputAllPagesToDisk(pages map[string]*Page, outputDir string, baseURL string) error {
 	os.RemoveAll(outputDir)
 
 	err := os.MkdirAll(outputDir, 0755)
@@ -19,31 +25,127 @@ func (p *Parser) OutputAllPagesToDisk(pages map[string]*Page, outputDir string)
 
 	allPageTitles := p.AllPageTitles(pages)
 
-	for key, page := range pages {
-		outputPath := outputDir + key + ".html"
+	for _, page := range pages {
+		err = p.outputPageToDisk(page, allPageTitles, outputDir, baseURL)
+		if err != nil {
+			return err
+		}
+	}
 
-		f, err := os.Create(outputPath)
+	for _, title := range p.linkedCategoryQueries(pages) {
+		page, err := p.CategoryQueryPage(pages, title)
 		if err != nil {
-			return fmt.Errorf("failed to create file %s: %w", outputPath, err)
+			slog.Warn("Skipping invalid category query", "page", title, "error", err)
+			continue
 		}
 
-		defer f.Close()
+		err = p.outputPageToDisk(page, allPageTitles, outputDir, baseURL)
+		if err != nil {
+			return err
+		}
+	}
 
-		w := bufio.NewWriter(f)
+	for number := 2; number <= p.AllPagesCount(pages); number++ {
+		page, err := p.AllPagesPage(pages, number)
+		if err != nil {
+			return fmt.Errorf("failed to create %s page: %w", AllPagesTitleFor(number), err)
+		}
 
-		err = PageTemplate.Execute(w, PageTemplateData{
-			AllPageTitles: allPageTitles,
-			Page:          page,
-			Content:       template.HTML(string(page.ParsedContent)),
-		})
+		err = p.outputPageToDisk(page, allPageTitles, outputDir, baseURL)
 		if err != nil {
-			return fmt.Errorf("failed to execute template: %w", err)
+			return err
 		}
+	}
+
+	for _, category := range append([]string{""}, p.AllCategories(pages)...) {
+		for _, window := range append([]int{0}, RecentChangesWindows...) {
+			// The unfiltered page is output with the other special pages.
+			if category == "" && window == 0 {
+				continue
+			}
+
+			filter := RecentChangesFilter{Category: category, Days: window}
 
-		err = w.Flush()
+			page, err := p.RecentChangesPage(pages, filter, false)
+			if err != nil {
+				return fmt.Errorf("failed to create %s page: %w", RecentChangesTitleFor(filter), err)
+			}
+
+			err = p.outputPageToDisk(page, allPageTitles, outputDir, baseURL)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	now := p.Now()
+	for _, day := range AllMonthDays() {
+		title := OnThisDayTitleFor(day.Month(), day.Day())
+
+		page, err := p.OnThisDayPage(pages, title, day.Month(), day.Day(), now)
 		if err != nil {
-			return fmt.Errorf("failed to flush template: %w", err)
+			return fmt.Errorf("failed to create %s page: %w", title, err)
 		}
+
+		err = p.outputPageToDisk(page, allPageTitles, outputDir, baseURL)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = outputFileToDisk(outputDir, CalendarTitle+".ics", p.Calendar(pages, "", baseURL))
+	if err != nil {
+		return err
+	}
+
+	for _, category := range p.AllCategories(pages) {
+		err = outputFileToDisk(outputDir, CalendarTitleFor(category)+".ics", p.Calendar(pages, category, baseURL))
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, category := range append([]string{""}, p.AllCategories(pages)...) {
+		for _, format := range FeedFormats {
+			feed, err := p.Feed(pages, category, format, baseURL)
+			if err != nil {
+				return fmt.Errorf("failed to create %s feed: %w", FeedTitleFor(category), err)
+			}
+
+			err = outputFileToDisk(outputDir, FeedTitleFor(category)+"."+format.Extension, feed)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	randomIndex, err := p.RandomIndex(pages)
+	if err != nil {
+		return err
+	}
+
+	err = outputFileToDisk(outputDir, RandomIndexName, randomIndex)
+	if err != nil {
+		return err
+	}
+
+	// Sitemaps must contain absolute URLs, so one can only be made with a base
+	// URL.
+	if baseURL != "" {
+		sitemap, err := p.SitemapXML(pages, baseURL)
+		if err != nil {
+			return fmt.Errorf("failed to create sitemap: %w", err)
+		}
+
+		err = outputFileToDisk(outputDir, "sitemap.xml", sitemap)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = outputFileToDisk(outputDir, "robots.txt", Robots(baseURL, true))
+	if err != nil {
+		return err
 	}
 
 	err = cp.Copy("pkg/static/static/.", outputDir)
@@ -53,3 +155,95 @@ func (p *Parser) OutputAllPagesToDisk(pages map[string]*Page, outputDir string)
 
 	return nil
 }
+
+func (p *Parser) outputPageToDisk(page *Page, allPageTitles []string, outputDir string, baseURL string) error {
+	outputPath := filepath.Join(outputDir, page.Title+".html")
+
+	err := os.MkdirAll(filepath.Dir(outputPath), 0755)
+	if err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", outputPath, err)
+	}
+
+	f, err := os.Create(outputPath)
+	if err != nil {
+		return fmt.Errorf("failed to create file %s: %w", outputPath, err)
+	}
+	defer utils.DeferredClose(f)
+
+	contributors, err := p.PageContributors(page)
+	if err != nil {
+		slog.Warn("Failed to find page contributors", "page", page.Title, "error", err)
+	}
+
+	w := bufio.NewWriter(f)
+
+	err = PageTemplate.Execute(w, PageTemplateData{
+		AllPageTitles: allPageTitles,
+		Page:          page,
+		Content:       template.HTML(string(page.ParsedContent)),
+		Dates:         p.Dates,
+		Feeds:         FeedLinksFor(page),
+		Contributors:  contributors,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to execute template: %w", err)
+	}
+
+	err = w.Flush()
+	if err != nil {
+		return fmt.Errorf("failed to flush template: %w", err)
+	}
+
+	for _, representation := range Representations {
+		data, err := p.Represent(page, representation, baseURL)
+		if errors.Is(err, ErrNoSource) {
+			continue
+		} else if err != nil {
+			return fmt.Errorf("failed to create %s representation of %s: %w", representation.Extension, page.Title, err)
+		}
+
+		err = outputFileToDisk(outputDir, page.Title+"."+representation.Extension, data)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// linkedCategoryQueries returns the category query pages linked to from other
+// pages, which are only created on demand. Links to a section of a query link
+// to the query's page.
+func (p *Parser) linkedCategoryQueries(pages map[string]*Page) []string {
+	queries := make([]string, 0)
+	seen := make(map[string]bool)
+
+	for _, title := range p.AllPageTitles(pages) {
+		for _, link := range pages[title].LinksTo {
+			link, _, _ = strings.Cut(link, "#")
+			if _, ok := pages[link]; ok || seen[link] || !strings.HasPrefix(link, CategoryPrefix) {
+				continue
+			}
+			seen[link] = true
+			queries = append(queries, link)
+		}
+	}
+
+	return queries
+}
+
+func outputFileToDisk(outputDir string, name string, data []byte) error {
+	outputPath := filepath.Join(outputDir, name)
+
+	err := os.MkdirAll(filepath.Dir(outputPath), 0755)
+	if err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", outputPath, err)
+	}
+
+	err = os.WriteFile(outputPath, data, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write file %s: %w", outputPath, err)
+	}
+
+	return nil
+}
@@ -5,26 +5,55 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"time"
 
 	"github.com/yuin/goldmark"
 	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/util"
 	"go.abhg.dev/goldmark/frontmatter"
 	"go.abhg.dev/goldmark/wikilink"
 
 	"pkg.fogo.sh/almanac/pkg/content/extensions"
+	"pkg.fogo.sh/almanac/pkg/history"
 	"pkg.fogo.sh/almanac/pkg/utils"
 )
 
 type PageMeta struct {
-	Categories []string   `toml:"categories"`
-	Date       *time.Time `toml:"date"`
-	Redirect   *string    `toml:"redirect"`
-	Root       bool       `toml:"root"`
-	YoutubeId  string     `toml:"youtube_id"`
+	Categories []string `toml:"categories" yaml:"categories"`
+	Date       *Date    `toml:"date" yaml:"date"`
+	EndDate    *Date    `toml:"end_date" yaml:"end_date"`
+	Redirect   *string  `toml:"redirect" yaml:"redirect"`
+	Root       bool     `toml:"root" yaml:"root"`
+	YoutubeId  string   `toml:"youtube_id" yaml:"youtube_id"`
+	// Draft hides the page from the public.
+	Draft bool `toml:"draft" yaml:"draft"`
+	// PublishAt hides the page from the public until the date.
+	PublishAt *Date `toml:"publish_at" yaml:"publish_at"`
+	// ExpireAt hides the page from the public from the date.
+	ExpireAt *Date `toml:"expire_at" yaml:"expire_at"`
+	// Listing configures how a category's members are listed, on category
+	// description pages.
+	Listing *ListingConfig `toml:"listing" yaml:"listing"`
 }
 
+// builtinFields are the frontmatter fields decoded into PageMeta.
+var builtinFields = func() []string {
+	fields := make([]string, 0)
+
+	metaType := reflect.TypeOf(PageMeta{})
+	for i := 0; i < metaType.NumField(); i++ {
+		name, _, _ := strings.Cut(metaType.Field(i).Tag.Get("toml"), ",")
+		if name != "" && name != "-" {
+			fields = append(fields, name)
+		}
+	}
+
+	return fields
+}()
+
 type Page struct {
 	Title         string
 	Path          *string
@@ -32,13 +61,75 @@ type Page struct {
 	Backlinks     []string
 	Meta          PageMeta
 	ParsedContent []byte
+	ModTime       time.Time
+	// LastChange is the most recent commit to the page's file, if the content
+	// directory is a git repository and the file has no uncommitted changes.
+	LastChange *history.Change
+	// HasHistory is set if the page's file has been committed to the content
+	// repository.
+	HasHistory bool
+	// InheritedCategories are ancestors of the page's categories that roll up
+	// the members of their subcategories.
+	InheritedCategories []string
+	// Infobox summarises the page's frontmatter, if one of its categories
+	// configures an infobox.
+	Infobox *Infobox
+	// Hidden is why the page is hidden from the public, if it is. Hidden pages
+	// are only discovered when drafts are included.
+	Hidden string
+
+	// Fields are the page's custom frontmatter fields.
+	Fields Fields
+
+	// fields is the page's raw frontmatter, including the fields decoded into
+	// Meta, used to sort and validate by any field.
+	fields map[string]interface{}
+}
+
+// Categories returns the categories the page is a member of, both directly
+// and inherited.
+func (p *Page) Categories() []string {
+	if len(p.InheritedCategories) == 0 {
+		return p.Meta.Categories
+	}
+
+	return append(append([]string{}, p.Meta.Categories...), p.InheritedCategories...)
+}
+
+// InCategory reports whether the page is a member of the given category.
+// Category description pages aren't members of their parent categories.
+func (p *Page) InCategory(category string) bool {
+	if p.IsCategoryDescription() {
+		return false
+	}
+
+	for _, c := range p.Categories() {
+		if c == category {
+			return true
+		}
+	}
+	return false
 }
 
 type Parser struct {
 	DiscordUserResolver *extensions.DiscordUserResolver
+	Dates               DateConfig
+	Sitemap             SitemapConfig
+	Contributors        []ContributorConfig
+	Categories          map[string]CategoryConfig
+	// IncludeDrafts discovers drafts and pages that aren't published yet or
+	// have expired, which are otherwise hidden.
+	IncludeDrafts bool
+}
+
+// Now returns the current time in the configured timezone.
+func (p *Parser) Now() time.Time {
+	return time.Now().In(p.Dates.location())
 }
 
-func (p *Parser) ParsePageFile(path string) (Page, error) {
+// ParsePageFile parses the page at path. Links to pages missing from index are
+// rendered as red links; a nil index renders every link as existing.
+func (p *Parser) ParsePageFile(path string, index PageIndex) (Page, error) {
 	f, err := os.Open(path)
 	if err != nil {
 		return Page{}, fmt.Errorf("failed to open file: %w", err)
@@ -56,30 +147,51 @@ func (p *Parser) ParsePageFile(path string) (Page, error) {
 		return Page{}, fmt.Errorf("failed to read file: %w", err)
 	}
 
+	page, err := p.ParsePage(path, content, index)
+	if err != nil {
+		return Page{}, err
+	}
+
+	page.ModTime = stat.ModTime()
+
+	return page, nil
+}
+
+// ParsePage parses the markdown content of a page, as if it had been read
+// from the file at path. Links to pages missing from index are rendered as red
+// links.
+func (p *Parser) ParsePage(path string, content []byte, index PageIndex) (Page, error) {
 	var linksTo = make([]string, 0)
 
-	md := goldmark.New(goldmark.WithExtensions(
-		&frontmatter.Extender{},
-		&wikilink.Extender{
-			Resolver: WikiLinkResolver{
-				recordDestination: func(destination []byte) error {
-					linksTo = append(linksTo, string(destination))
-					return nil
-				},
-			},
+	resolver := WikiLinkResolver{
+		recordDestination: func(destination []byte) error {
+			linksTo = append(linksTo, string(destination))
+			return nil
 		},
-		extensions.NewDiscordMention(p.DiscordUserResolver),
-	))
+	}
+
+	md := goldmark.New(
+		goldmark.WithExtensions(
+			&frontmatter.Extender{Formats: frontmatterFormats},
+			&wikilink.Extender{Resolver: resolver},
+			extensions.NewDiscordMention(p.DiscordUserResolver),
+		),
+		// Takes priority over the renderer registered by the wikilink extension.
+		goldmark.WithRendererOptions(renderer.WithNodeRenderers(
+			util.Prioritized(newWikiLinkRenderer(resolver, index), 100),
+		)),
+	)
 
 	ctx := parser.NewContext()
 
 	var buf bytes.Buffer
-	err = md.Convert(content, &buf, parser.WithContext(ctx))
+	err := md.Convert(content, &buf, parser.WithContext(ctx))
 	if err != nil {
 		return Page{}, fmt.Errorf("failed to parse markdown: %w", err)
 	}
 
 	var pageMeta PageMeta
+	var fields map[string]interface{}
 
 	data := frontmatter.Get(ctx)
 
@@ -87,8 +199,23 @@ func (p *Parser) ParsePageFile(path string) (Page, error) {
 		if err := data.Decode(&pageMeta); err != nil {
 			return Page{}, fmt.Errorf("failed to decode frontmatter: %w", err)
 		}
+
+		if err := data.Decode(&fields); err != nil {
+			return Page{}, fmt.Errorf("failed to decode frontmatter: %w", err)
+		}
+	}
+
+	if pageMeta.Listing != nil {
+		if err := pageMeta.Listing.Validate(); err != nil {
+			return Page{}, fmt.Errorf("failed to decode frontmatter: %w", err)
+		}
 	}
 
+	pageMeta.Date.localize(p.Dates.location())
+	pageMeta.EndDate.localize(p.Dates.location())
+	pageMeta.PublishAt.localize(p.Dates.location())
+	pageMeta.ExpireAt.localize(p.Dates.location())
+
 	pageTitle := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
 
 	return Page{
@@ -97,5 +224,7 @@ func (p *Parser) ParsePageFile(path string) (Page, error) {
 		Path:          &path,
 		Meta:          pageMeta,
 		ParsedContent: buf.Bytes(),
+		Fields:        customFields(fields),
+		fields:        fields,
 	}, nil
 }
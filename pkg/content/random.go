@@ -0,0 +1,78 @@
+package content
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+)
+
+const RandomTitle = "$Random"
+
+// RandomIndexName is the file the static site's random page picks from.
+const RandomIndexName = RandomTitle + "/index.json"
+
+const randomPageContent = `<p id="random-status">Taking you to a random page…</p>
+<noscript><p>Picking a random page requires JavaScript.</p></noscript>
+<script src="/assets/js/random.js"></script>`
+
+// RandomCandidates returns the titles of the pages a random page is picked
+// from: content pages that aren't redirects, optionally in a category.
+func (p *Parser) RandomCandidates(pages map[string]*Page, category string) []string {
+	notRedirect := false
+
+	titles := make([]string, 0)
+	for _, page := range p.FilterPages(pages, PageFilter{Category: category, Redirect: &notRedirect}) {
+		titles = append(titles, page.Title)
+	}
+
+	return titles
+}
+
+// RandomPage returns the title of a random page, optionally in a category, or
+// false if there are no pages to pick from.
+func (p *Parser) RandomPage(pages map[string]*Page, category string) (string, bool) {
+	candidates := p.RandomCandidates(pages, category)
+	if len(candidates) == 0 {
+		return "", false
+	}
+
+	return candidates[rand.Intn(len(candidates))], true
+}
+
+// RandomPlaceholderPage creates the $Random page for static sites, which
+// picks a page client-side from the index written by RandomIndex. The server
+// redirects to a random page instead.
+func RandomPlaceholderPage() *Page {
+	return &Page{
+		Title:         RandomTitle,
+		LinksTo:       []string{},
+		ParsedContent: []byte(randomPageContent),
+	}
+}
+
+type randomIndex struct {
+	Pages []string `json:"pages"`
+	// Categories maps category names to the indexes of their pages.
+	Categories map[string][]int `json:"categories"`
+}
+
+// RandomIndex renders the pages a random page is picked from as JSON.
+func (p *Parser) RandomIndex(pages map[string]*Page) ([]byte, error) {
+	index := randomIndex{
+		Pages:      p.RandomCandidates(pages, ""),
+		Categories: make(map[string][]int),
+	}
+
+	for i, title := range index.Pages {
+		for _, category := range pages[title].Categories() {
+			index.Categories[category] = append(index.Categories[category], i)
+		}
+	}
+
+	data, err := json.Marshal(index)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal random page index: %w", err)
+	}
+
+	return data, nil
+}
@@ -0,0 +1,164 @@
+package content
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"sort"
+)
+
+const RecentChangesTitle = "$RecentChanges"
+
+const maxRecentChanges = 100
+
+// RecentChangesWindows are the time windows, in days, offered as filters.
+var RecentChangesWindows = []int{1, 7, 30, 90, 365}
+
+// RecentChangesTitleFor returns the title of the recent changes page for a
+// filter, as output to disk, such as "$RecentChanges/Events/Last 7 days".
+func RecentChangesTitleFor(filter RecentChangesFilter) string {
+	title := RecentChangesTitle
+	if filter.Category != "" {
+		title += "/" + filter.Category
+	}
+	if filter.Days != 0 {
+		title += "/Last " + recentChangesWindowLabel(filter.Days)
+	}
+	return title
+}
+
+func recentChangesWindowLabel(days int) string {
+	if days == 1 {
+		return "1 day"
+	}
+	return fmt.Sprintf("%d days", days)
+}
+
+type RecentChangesFilter struct {
+	// Category restricts the listing to pages in a category, if not empty.
+	Category string
+	// Days restricts the listing to changes in the last number of days, if
+	// not zero.
+	Days int
+}
+
+// RecentChangesPage creates a page listing content pages by their last
+// modification, newest first and grouped by day. If showFilters is true, the
+// page includes a form to change the filter, otherwise it links to the other
+// filtered pages output to disk.
+func (p *Parser) RecentChangesPage(
+	pages map[string]*Page,
+	filter RecentChangesFilter,
+	showFilters bool,
+) (*Page, error) {
+	now := p.Now()
+
+	changedPages := make([]*Page, 0)
+	for _, page := range pages {
+		if page.Path == nil {
+			continue
+		}
+
+		if filter.Category != "" && !page.InCategory(filter.Category) {
+			continue
+		}
+
+		if filter.Days != 0 && page.ModTime.Before(now.AddDate(0, 0, -filter.Days)) {
+			continue
+		}
+
+		changedPages = append(changedPages, page)
+	}
+
+	sort.Slice(changedPages, func(i, j int) bool {
+		if !changedPages[i].ModTime.Equal(changedPages[j].ModTime) {
+			return changedPages[i].ModTime.After(changedPages[j].ModTime)
+		}
+		return changedPages[i].Title < changedPages[j].Title
+	})
+
+	if len(changedPages) > maxRecentChanges {
+		changedPages = changedPages[:maxRecentChanges]
+	}
+
+	days := make([]RecentChangesDay, 0)
+
+	for _, page := range changedPages {
+		modTime := page.ModTime.In(now.Location())
+		day := modTime.Format(orDefault(p.Dates.Format, "Jan 2, 2006"))
+
+		if len(days) == 0 || days[len(days)-1].Day != day {
+			days = append(days, RecentChangesDay{Day: day})
+		}
+
+		change := RecentChange{
+			Title: page.Title,
+			Time:  modTime.Format("15:04"),
+		}
+
+		if page.LastChange != nil {
+			change.Size = page.LastChange.Size
+			change.SizeDelta = fmt.Sprintf("%+d", page.LastChange.SizeDelta)
+			change.Author = page.LastChange.Author
+			change.Summary = page.LastChange.Summary()
+		} else if stat, err := os.Stat(*page.Path); err == nil {
+			change.Size = stat.Size()
+		}
+
+		days[len(days)-1].Changes = append(days[len(days)-1].Changes, change)
+	}
+
+	categories := p.AllCategories(pages)
+	sort.Strings(categories)
+
+	title := RecentChangesTitle
+	categoryLinks := make([]RecentChangesLink, 0)
+	windowLinks := make([]RecentChangesLink, 0)
+
+	if !showFilters {
+		title = RecentChangesTitleFor(filter)
+
+		for _, category := range append([]string{""}, categories...) {
+			categoryLinks = append(categoryLinks, RecentChangesLink{
+				Label:    orDefault(category, "All"),
+				Title:    RecentChangesTitleFor(RecentChangesFilter{Category: category, Days: filter.Days}),
+				Selected: category == filter.Category,
+			})
+		}
+
+		for _, window := range append([]int{0}, RecentChangesWindows...) {
+			label := "All time"
+			if window != 0 {
+				label = recentChangesWindowLabel(window)
+			}
+
+			windowLinks = append(windowLinks, RecentChangesLink{
+				Label:    label,
+				Title:    RecentChangesTitleFor(RecentChangesFilter{Category: filter.Category, Days: window}),
+				Selected: window == filter.Days,
+			})
+		}
+	}
+
+	var buf bytes.Buffer
+	err := RecentChangesTemplate.Execute(&buf, RecentChangesData{
+		Days:          days,
+		ShowFilters:   showFilters,
+		Filter:        filter,
+		Categories:    categories,
+		Windows:       RecentChangesWindows,
+		CategoryLinks: categoryLinks,
+		WindowLinks:   windowLinks,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute template: %w", err)
+	}
+
+	// Recent changes aren't recorded as links, as every page would otherwise
+	// have a backlink to this one.
+	return &Page{
+		Title:         title,
+		LinksTo:       []string{},
+		ParsedContent: buf.Bytes(),
+	}, nil
+}
@@ -0,0 +1,320 @@
+package content
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+)
+
+// Representation is an alternate form of a page, served with its own file
+// extension alongside the rendered HTML page.
+type Representation struct {
+	Extension   string
+	ContentType string
+}
+
+var Representations = []Representation{
+	{Extension: "md", ContentType: "text/markdown; charset=utf-8"},
+	{Extension: "txt", ContentType: "text/plain; charset=utf-8"},
+	{Extension: "json", ContentType: "application/json; charset=utf-8"},
+}
+
+// FindRepresentation returns the representation with the given extension.
+func FindRepresentation(extension string) (Representation, bool) {
+	for _, representation := range Representations {
+		if representation.Extension == extension {
+			return representation, true
+		}
+	}
+	return Representation{}, false
+}
+
+// ErrNoSource is returned when requesting the markdown source of a page that
+// has none, such as a special page.
+var ErrNoSource = errors.New("page has no source")
+
+// Represent renders a page in the given representation.
+func (p *Parser) Represent(page *Page, representation Representation, baseURL string) ([]byte, error) {
+	switch representation.Extension {
+	case "md":
+		return PageSource(page)
+	case "txt":
+		return PageText(page), nil
+	case "json":
+		return p.PageJSON(page, baseURL)
+	}
+
+	return nil, fmt.Errorf("unknown representation %q", representation.Extension)
+}
+
+// PageSource returns the markdown source of a page, including its frontmatter.
+func PageSource(page *Page) ([]byte, error) {
+	if page.Path == nil {
+		return nil, ErrNoSource
+	}
+
+	source, err := os.ReadFile(*page.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", *page.Path, err)
+	}
+
+	return source, nil
+}
+
+// PageText renders a page as plain text, keeping paragraphs, list items and
+// preformatted blocks on their own lines.
+func PageText(page *Page) []byte {
+	var buf bytes.Buffer
+	buf.WriteString(page.Title)
+	buf.WriteString("\n\n")
+
+	for i, block := range textBlocks(page.ParsedContent) {
+		if i > 0 {
+			if block.listItem && block.previousListItem {
+				buf.WriteString("\n")
+			} else {
+				buf.WriteString("\n\n")
+			}
+		}
+		buf.WriteString(block.text)
+	}
+	buf.WriteString("\n")
+
+	return buf.Bytes()
+}
+
+type textBlock struct {
+	text             string
+	listItem         bool
+	previousListItem bool
+}
+
+// textList is a list open while splitting text blocks. Items of ordered lists
+// are numbered from next.
+type textList struct {
+	ordered bool
+	next    int
+}
+
+// textBlocks splits rendered page content into blocks of plain text at block
+// elements. Whitespace is collapsed, except within preformatted blocks.
+func textBlocks(content []byte) []textBlock {
+	tokenizer := html.NewTokenizer(bytes.NewReader(content))
+
+	blocks := make([]textBlock, 0)
+
+	var current strings.Builder
+	preDepth := 0
+	listItem := false
+	marker := ""
+
+	// lists holds the open lists, innermost last.
+	lists := make([]textList, 0)
+
+	flush := func() {
+		text := current.String()
+		current.Reset()
+
+		if preDepth > 0 {
+			text = strings.Trim(text, "\n")
+		} else {
+			text = strings.Join(strings.Fields(text), " ")
+		}
+		if text == "" {
+			return
+		}
+
+		block := textBlock{text: text, listItem: listItem}
+		if listItem {
+			block.text = marker + " " + text
+		}
+		if len(blocks) > 0 {
+			block.previousListItem = blocks[len(blocks)-1].listItem
+		}
+		blocks = append(blocks, block)
+		listItem = false
+	}
+
+	for {
+		tokenType := tokenizer.Next()
+		switch tokenType {
+		case html.ErrorToken:
+			flush()
+			return blocks
+		case html.TextToken:
+			current.Write(tokenizer.Text())
+		case html.StartTagToken, html.EndTagToken, html.SelfClosingTagToken:
+			name, hasAttr := tokenizer.TagName()
+			if _, ok := blockElements[string(name)]; !ok {
+				if string(name) != "ul" && string(name) != "ol" {
+					continue
+				}
+			}
+
+			flush()
+
+			start := tokenType == html.StartTagToken
+			switch string(name) {
+			case "pre":
+				if start {
+					preDepth++
+				} else if preDepth > 0 {
+					preDepth--
+				}
+			case "ul":
+				if start {
+					lists = append(lists, textList{})
+				} else if len(lists) > 0 {
+					lists = lists[:len(lists)-1]
+				}
+			case "ol":
+				if start {
+					lists = append(lists, textList{ordered: true, next: orderedListStart(tokenizer, hasAttr)})
+				} else if len(lists) > 0 {
+					lists = lists[:len(lists)-1]
+				}
+			case "li":
+				listItem = start
+				if start {
+					marker = "-"
+					if len(lists) > 0 && lists[len(lists)-1].ordered {
+						marker = fmt.Sprintf("%d.", lists[len(lists)-1].next)
+						lists[len(lists)-1].next++
+					}
+				}
+			}
+		case html.CommentToken, html.DoctypeToken:
+		}
+	}
+}
+
+// orderedListStart returns the number of the first item of an ordered list,
+// from its start attribute.
+func orderedListStart(tokenizer *html.Tokenizer, hasAttr bool) int {
+	for hasAttr {
+		var key, value []byte
+		key, value, hasAttr = tokenizer.TagAttr()
+		if string(key) != "start" {
+			continue
+		}
+		if start, err := strconv.Atoi(string(value)); err == nil {
+			return start
+		}
+	}
+
+	return 1
+}
+
+// PageDocumentDate is a frontmatter date as represented in JSON.
+type PageDocumentDate struct {
+	Value string    `json:"value"`
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+	Circa bool      `json:"circa,omitempty"`
+}
+
+type PageDocumentChange struct {
+	Hash    string    `json:"hash"`
+	Author  string    `json:"author"`
+	Time    time.Time `json:"time"`
+	Summary string    `json:"summary"`
+}
+
+// PageDocument is a page's metadata and links, as represented in JSON.
+type PageDocument struct {
+	Title      string              `json:"title"`
+	URL        string              `json:"url"`
+	Categories []string            `json:"categories"`
+	Date       *PageDocumentDate   `json:"date,omitempty"`
+	EndDate    *PageDocumentDate   `json:"end_date,omitempty"`
+	Redirect   *string             `json:"redirect,omitempty"`
+	YoutubeId  string              `json:"youtube_id,omitempty"`
+	LinksTo    []string            `json:"links_to"`
+	Backlinks  []string            `json:"backlinks"`
+	Modified   *time.Time          `json:"modified,omitempty"`
+	LastChange *PageDocumentChange `json:"last_change,omitempty"`
+	// Fields are the page's custom frontmatter fields.
+	Fields map[string]interface{} `json:"fields"`
+}
+
+func newPageDocumentDate(date *Date) *PageDocumentDate {
+	if date == nil {
+		return nil
+	}
+
+	layout := "2006-01-02"
+	switch {
+	case date.Precision == DatePrecisionYear:
+		layout = "2006"
+	case date.Precision == DatePrecisionMonth:
+		layout = "2006-01"
+	case date.HasClock:
+		layout = time.RFC3339
+	}
+
+	return &PageDocumentDate{
+		Value: date.Time.Format(layout),
+		Start: date.Start(),
+		End:   date.End(),
+		Circa: date.Circa,
+	}
+}
+
+// NewPageDocument collects a page's metadata and links for JSON output.
+func NewPageDocument(page *Page, baseURL string) PageDocument {
+	document := PageDocument{
+		Title:      page.Title,
+		URL:        PageURL(baseURL, page.Title),
+		Categories: page.Meta.Categories,
+		Date:       newPageDocumentDate(page.Meta.Date),
+		EndDate:    newPageDocumentDate(page.Meta.EndDate),
+		Redirect:   page.Meta.Redirect,
+		YoutubeId:  page.Meta.YoutubeId,
+		LinksTo:    page.LinksTo,
+		Backlinks:  page.Backlinks,
+		Fields:     page.Fields.JSON(),
+	}
+
+	if document.Categories == nil {
+		document.Categories = []string{}
+	}
+	if document.LinksTo == nil {
+		document.LinksTo = []string{}
+	}
+	if document.Backlinks == nil {
+		document.Backlinks = []string{}
+	}
+
+	if !page.ModTime.IsZero() {
+		modified := page.ModTime
+		document.Modified = &modified
+	}
+
+	if page.LastChange != nil {
+		document.LastChange = &PageDocumentChange{
+			Hash:    page.LastChange.Hash,
+			Author:  page.LastChange.Author,
+			Time:    page.LastChange.Time,
+			Summary: page.LastChange.Summary(),
+		}
+	}
+
+	return document
+}
+
+// PageJSON renders a page's metadata and links as JSON.
+func (p *Parser) PageJSON(page *Page, baseURL string) ([]byte, error) {
+	data, err := json.MarshalIndent(NewPageDocument(page, baseURL), "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal page: %w", err)
+	}
+
+	return append(data, '\n'), nil
+}
@@ -0,0 +1,77 @@
+package content
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"time"
+)
+
+type SitemapConfig struct {
+	ExcludeRedirects    bool
+	ExcludeSpecialPages bool
+}
+
+type sitemapURL struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+type sitemapURLSet struct {
+	XMLName xml.Name     `xml:"http://www.sitemaps.org/schemas/sitemap/0.9 urlset"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+// SitemapXML renders a sitemap.xml listing every page, excluding redirects and
+// special pages if configured to.
+func (p *Parser) SitemapXML(pages map[string]*Page, baseURL string) ([]byte, error) {
+	urlSet := sitemapURLSet{}
+
+	for _, title := range p.AllPageTitles(pages) {
+		page := pages[title]
+
+		if p.Sitemap.ExcludeRedirects && page.Meta.Redirect != nil {
+			continue
+		}
+
+		if p.Sitemap.ExcludeSpecialPages && strings.HasPrefix(page.Title, "$") {
+			continue
+		}
+
+		var lastMod time.Time
+		if !page.ModTime.IsZero() {
+			lastMod = page.ModTime
+		} else if page.Meta.Date != nil {
+			lastMod = page.Meta.Date.Start()
+		}
+
+		url := sitemapURL{Loc: PageURL(baseURL, page.Title)}
+		if !lastMod.IsZero() {
+			url.LastMod = lastMod.Format(time.RFC3339)
+		}
+
+		urlSet.URLs = append(urlSet.URLs, url)
+	}
+
+	data, err := xml.MarshalIndent(urlSet, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal sitemap: %w", err)
+	}
+
+	return append([]byte(xml.Header), data...), nil
+}
+
+// Robots renders a robots.txt, either allowing crawling and pointing crawlers
+// at the sitemap, or disallowing crawling entirely. Sitemaps must be linked by
+// absolute URL, so the sitemap is left out without a base URL.
+func Robots(baseURL string, allowCrawling bool) []byte {
+	if !allowCrawling {
+		return []byte("User-agent: *\nDisallow: /\n")
+	}
+
+	if baseURL == "" {
+		return []byte("User-agent: *\nAllow: /\n")
+	}
+
+	return []byte(fmt.Sprintf("User-agent: *\nAllow: /\n\nSitemap: %s\n", PageURL(baseURL, "sitemap.xml")))
+}
@@ -2,20 +2,42 @@ package content
 
 import (
 	"html/template"
+
+	"pkg.fogo.sh/almanac/pkg/history"
 )
 
 type PageTemplateData struct {
 	AllPageTitles []string
 	Page          *Page
 	Content       template.HTML
+	Dates         DateConfig
+	Feeds         []FeedLink
+	// ShowHistory enables the link to the page's history.
+	ShowHistory bool
+	// ShowEdit enables the link to edit the page.
+	ShowEdit bool
+	// Revision is set when showing a historical revision of the page.
+	Revision *history.Change
+	// Contributors is set if the page's authorship is known from git.
+	Contributors *Contributors
+	// CanPreviewDrafts enables the link to start or end a preview session
+	// showing hidden pages.
+	CanPreviewDrafts bool
+	// PreviewingDrafts is set while hidden pages are shown.
+	PreviewingDrafts bool
 }
 
-var pageTemplateContent = `<!DOCTYPE html>
+var pageTemplateContent = `{{ define "contributor" -}}
+{{ if .Page }}<a href="/{{ .Page }}">{{ .Name }}</a>{{ else }}{{ .Name }}{{ end }}
+{{- end }}<!DOCTYPE html>
 <html>
 	<head>
 		<title>{{ .Page.Title }}</title>
 		<link rel="stylesheet" href="/assets/css/main.css">
 		<link rel="icon" type="image/svg+xml" href="/favicon.svg">
+		{{ range .Feeds }}
+		<link rel="alternate" type="{{ .ContentType }}" title="{{ .Title }}" href="{{ .Href }}">
+		{{ end }}
 	</head>
 	<body>
 		<nav>
@@ -28,10 +50,43 @@ var pageTemplateContent = `<!DOCTYPE html>
 		<main>
 			<h1>{{ .Page.Title }}</h1>
 
+			{{ if or .ShowEdit .ShowHistory .CanPreviewDrafts }}
+			<p class="page-actions">
+				{{ if .ShowEdit }}<a href="/{{ .Page.Title }}/edit">Edit</a>{{ end }}
+				{{ if .ShowHistory }}<a href="/{{ .Page.Title }}/history">History</a>{{ end }}
+				{{ if .CanPreviewDrafts }}<a href="/_almanac/drafts?return=/{{ .Page.Title }}">{{ if .PreviewingDrafts }}Hide drafts{{ else }}Show drafts{{ end }}</a>{{ end }}
+			</p>
+			{{ end }}
+
+			{{ with .Page.Hidden }}
+			<p class="notice">This page is hidden from the public, as {{ . }}.</p>
+			{{ end }}
+
+			{{ if .Revision }}
+			<p class="notice">
+				This is an old revision of this page, as edited by {{ .Revision.Author }}
+				on {{ .Dates.FormatTime .Revision.Time }}{{ with .Revision.Summary }} (<i>{{ . }}</i>){{ end }}.
+				<a href="/{{ .Page.Title }}">View the current version</a>.
+			</p>
+			{{ end }}
+
 			{{ if .Page.Meta.Redirect }}
 			<p>↳ <a href="/{{ .Page.Meta.Redirect }}">{{ .Page.Meta.Redirect }}</a></p>
 			{{ end }}
 
+			{{ with .Page.Infobox }}
+			<aside class="infobox">
+				<table>
+				{{ range .Rows }}
+					<tr>
+						<th>{{ .Label }}</th>
+						<td>{{ .Value }}</td>
+					</tr>
+				{{ end }}
+				</table>
+			</aside>
+			{{ end }}
+
 			{{ if .Page.Meta.Categories }}
 			<p>
 			{{ range .Page.Meta.Categories }}
@@ -41,7 +96,7 @@ var pageTemplateContent = `<!DOCTYPE html>
 			{{ end }}
 
 			{{ if .Page.Meta.Date }}
-			<p>{{ .Page.Meta.Date.Format "Aug 2, 2006" }}</p>
+			<p>{{ .Page.Meta.FormatDate $.Dates }}</p>
 			{{ end }}
 
 			{{ if .Page.Meta.YoutubeId }}
@@ -66,6 +121,21 @@ var pageTemplateContent = `<!DOCTYPE html>
 				</ul>
 			</section>
 			{{ end }}
+
+			{{ with .Contributors }}
+			<section class="contributors">
+				<h2>Contributors</h2>
+				<p>
+					Created by {{ template "contributor" .FirstAuthor }},
+					last edited by {{ template "contributor" .LastEditor }} on {{ .LastEdited }}.
+				</p>
+				<ul>
+				{{ range .Authors }}
+					<li>{{ template "contributor" . }} ({{ .Lines }} {{ if eq .Lines 1 }}line{{ else }}lines{{ end }})</li>
+				{{ end }}
+				</ul>
+			</section>
+			{{ end }}
 		</main>
 	</body>
 </html>`
@@ -85,10 +155,405 @@ var linkListingTemplateContent = `<ul>
 
 var LinkListingTemplate *template.Template
 
+var categoryTemplateContent = `{{ if .Description }}
+<div class="category-description">
+{{ .Description }}
+</div>
+<p><a href="/{{ .DescriptionTitle }}">About this category</a></p>
+{{ end }}
+{{ if .Subcategories }}
+<h2>Subcategories</h2>
+<ul>
+{{ range .Subcategories }}
+	<li><a href="/$Category:{{ . }}">{{ . }}</a></li>
+{{ end }}
+</ul>
+{{ end }}
+<h2>Pages</h2>
+{{ range .Groups }}
+{{ with .Heading }}<h3>{{ . }}</h3>{{ end }}
+<ul>
+{{ range .Members }}
+	<li><a href="/{{ .Title }}">{{ .Title }}</a>{{ with .Via }} <small>(in <a href="/$Category:{{ . }}">{{ . }}</a>)</small>{{ end }}</li>
+{{ end }}
+</ul>
+{{ end }}`
+
+var CategoryTemplate *template.Template
+
+var categoriesTemplateContent = `{{ define "categoryTree" }}
+<ul>
+{{ range . }}
+	<li>
+		<a href="/$Category:{{ .Name }}">{{ .Name }}</a> ({{ .Count }})
+		{{ with .Description }}<small>{{ . }}</small>{{ end }}
+		{{ if .Subcategories }}{{ template "categoryTree" .Subcategories }}{{ end }}
+	</li>
+{{ end }}
+</ul>
+{{ end }}
+{{ range .Groups }}
+<h2>{{ .Heading }}</h2>
+{{ template "categoryTree" .Categories }}
+{{ else }}
+<p>There are no categories yet.</p>
+{{ end }}`
+
+var CategoriesTemplate *template.Template
+
+type CategoryEntry struct {
+	Name          string
+	Count         int
+	Description   string
+	Subcategories []CategoryEntry
+}
+
+type CategoryEntryGroup struct {
+	Heading    string
+	Categories []CategoryEntry
+}
+
+type CategoriesData struct {
+	Groups []CategoryEntryGroup
+}
+
+var allPagesTemplateContent = `{{ define "pagination" }}
+{{ if gt .Count 1 }}
+<p class="pagination">
+	{{ if .Previous }}<a href="/{{ .Previous }}">Previous</a>{{ end }}
+	Page {{ .Number }} of {{ .Count }}
+	{{ if .Next }}<a href="/{{ .Next }}">Next</a>{{ end }}
+</p>
+{{ end }}
+{{ end }}
+<p class="letter-links">
+{{ range .Letters }}
+	<a href="{{ .Href }}">{{ .Letter }}</a>
+{{ end }}
+</p>
+{{ template "pagination" . }}
+{{ range .Groups }}
+<h2 id="{{ .Anchor }}">{{ .Heading }}</h2>
+<ul>
+{{ range .Titles }}
+	<li><a href="/{{ . }}">{{ . }}</a></li>
+{{ end }}
+</ul>
+{{ end }}
+{{ template "pagination" . }}`
+
+var AllPagesTemplate *template.Template
+
+type AllPagesLetter struct {
+	Letter string
+	// Href links to the letter's heading, on whichever page of the index
+	// it's on.
+	Href string
+}
+
+type AllPagesGroup struct {
+	Heading string
+	Anchor  string
+	Titles  []string
+}
+
+type AllPagesData struct {
+	Letters  []AllPagesLetter
+	Groups   []AllPagesGroup
+	Number   int
+	Count    int
+	Previous string
+	Next     string
+}
+
+type CategoryMember struct {
+	Title string
+	// Via is the subcategory the page is a member through, if it isn't a
+	// direct member.
+	Via string
+}
+
+type CategoryMemberGroup struct {
+	// Heading is empty if the listing isn't grouped.
+	Heading string
+	Members []CategoryMember
+}
+
+type CategoryData struct {
+	Category         string
+	Description      template.HTML
+	DescriptionTitle string
+	Subcategories    []string
+	Groups           []CategoryMemberGroup
+}
+
 type LinkListingData struct {
 	LinkList []string
 }
 
+var onThisDayTemplateContent = `<p>Things that happened on {{ .Day }} in previous years.</p>
+{{ range .Years }}
+<h2>{{ .Year }}</h2>
+<p>{{ .YearsAgo }} {{ if eq .YearsAgo 1 }}year{{ else }}years{{ end }} ago</p>
+<ul>
+{{ range .LinkList }}
+	<li><a href="/{{ . }}">{{ . }}</a></li>
+{{ end }}
+</ul>
+{{ else }}
+<p>Nothing has happened on this day yet.</p>
+{{ end }}
+<p><a href="/{{ .Previous }}">← Previous day</a> · <a href="/{{ .Next }}">Next day →</a></p>`
+
+var OnThisDayTemplate *template.Template
+
+type OnThisDayYear struct {
+	Year     int
+	YearsAgo int
+	LinkList []string
+}
+
+type OnThisDayData struct {
+	Day      string
+	Years    []OnThisDayYear
+	Previous string
+	Next     string
+}
+
+var recentChangesTemplateContent = `{{ if .ShowFilters }}
+<form method="get">
+	<label>
+		Category
+		<select name="category">
+			<option value="">All</option>
+			{{ range .Categories }}
+			<option{{ if eq . $.Filter.Category }} selected{{ end }}>{{ . }}</option>
+			{{ end }}
+		</select>
+	</label>
+	<label>
+		Changed in the last
+		<select name="days">
+			<option value="0">All time</option>
+			{{ range .Windows }}
+			<option value="{{ . }}"{{ if eq . $.Filter.Days }} selected{{ end }}>{{ . }} {{ if eq . 1 }}day{{ else }}days{{ end }}</option>
+			{{ end }}
+		</select>
+	</label>
+	<button type="submit">Show</button>
+</form>
+{{ else }}
+<p>
+	Category:
+	{{ range $i, $link := .CategoryLinks }}{{ if $i }} · {{ end }}{{ if .Selected }}<b>{{ .Label }}</b>{{ else }}<a href="/{{ .Title }}">{{ .Label }}</a>{{ end }}{{ end }}
+</p>
+<p>
+	Changed in the last:
+	{{ range $i, $link := .WindowLinks }}{{ if $i }} · {{ end }}{{ if .Selected }}<b>{{ .Label }}</b>{{ else }}<a href="/{{ .Title }}">{{ .Label }}</a>{{ end }}{{ end }}
+</p>
+{{ end }}
+{{ range .Days }}
+<h2>{{ .Day }}</h2>
+<ul>
+{{ range .Changes }}
+	<li>
+		{{ .Time }} <a href="/{{ .Title }}">{{ .Title }}</a>
+		({{ if .SizeDelta }}{{ .SizeDelta }} bytes, {{ end }}{{ .Size }} bytes total)
+		{{ if .Author }}— {{ .Author }}{{ if .Summary }}: <i>{{ .Summary }}</i>{{ end }}{{ end }}
+	</li>
+{{ end }}
+</ul>
+{{ else }}
+<p>No pages have changed.</p>
+{{ end }}`
+
+var RecentChangesTemplate *template.Template
+
+type RecentChange struct {
+	Title     string
+	Time      string
+	Size      int64
+	SizeDelta string
+	Author    string
+	Summary   string
+}
+
+type RecentChangesDay struct {
+	Day     string
+	Changes []RecentChange
+}
+
+// RecentChangesLink links to the recent changes page with another filter.
+type RecentChangesLink struct {
+	Label    string
+	Title    string
+	Selected bool
+}
+
+type RecentChangesData struct {
+	Days          []RecentChangesDay
+	ShowFilters   bool
+	Filter        RecentChangesFilter
+	Categories    []string
+	Windows       []int
+	CategoryLinks []RecentChangesLink
+	WindowLinks   []RecentChangesLink
+}
+
+var historyTemplateContent = `<p>Changes to <a href="/{{ .Title }}">{{ .Title }}</a>, newest first.</p>
+<ul>
+{{ range .Revisions }}
+	<li>
+		<a href="/{{ .Link }}">{{ .Time }}</a>
+		(<a href="/{{ $.Title }}/diff?to={{ .Hash }}">diff</a>)
+		<code>{{ .ShortHash }}</code>
+		{{ .Author }}
+		({{ .SizeDelta }} bytes{{ if ne .Path $.Path }}, as {{ .Path }}{{ end }})
+		{{ with .Summary }}— <i>{{ . }}</i>{{ end }}
+	</li>
+{{ else }}
+	<li>This page hasn't been committed yet.</li>
+{{ end }}
+</ul>`
+
+var HistoryTemplate *template.Template
+
+type HistoryRevision struct {
+	history.Change
+	Time      string
+	SizeDelta string
+	Link      string
+}
+
+type HistoryData struct {
+	Title     string
+	Path      string
+	Revisions []HistoryRevision
+}
+
+var diffTemplateContent = `<p>
+	Comparing <a href="/{{ .Title }}">{{ .Title }}</a>
+	from {{ if .From.Link }}<a href="/{{ .From.Link }}">{{ .From.Label }}</a>{{ else }}{{ .From.Label }}{{ end }}
+	to {{ if .To.Link }}<a href="/{{ .To.Link }}">{{ .To.Label }}</a>{{ else }}{{ .To.Label }}{{ end }}.
+</p>
+
+<h2>Fields</h2>
+{{ if .FieldChanges }}
+<ul>
+{{ range .FieldChanges }}
+	<li>
+		<code>{{ .Field }}</code>:
+		{{ if or .Added .Removed }}
+			{{ range .Added }}<ins>+{{ . }}</ins> {{ end }}
+			{{ range .Removed }}<del>−{{ . }}</del> {{ end }}
+		{{ else }}
+			{{ if .From }}<del>{{ .From }}</del>{{ else }}<i>unset</i>{{ end }}
+			→
+			{{ if .To }}<ins>{{ .To }}</ins>{{ else }}<i>unset</i>{{ end }}
+		{{ end }}
+	</li>
+{{ end }}
+</ul>
+{{ else }}
+<p>No fields changed.</p>
+{{ end }}
+
+<h2>Text</h2>
+<p class="diff-text">
+{{- range .TextDiff -}}
+{{- if eq .Operation "insert" }}<ins>{{ .Text }}</ins>
+{{- else if eq .Operation "delete" }}<del>{{ .Text }}</del>
+{{- else }}{{ .Text }}{{ end -}}
+{{- end -}}
+</p>
+
+<h2>Source</h2>
+<pre class="diff-source">
+{{- range .SourceDiff -}}
+{{- if eq .Operation "insert" }}<ins>+ {{ .Text }}</ins>
+{{ else if eq .Operation "delete" }}<del>- {{ .Text }}</del>
+{{ else }}  {{ .Text }}
+{{ end -}}
+{{- end -}}
+</pre>`
+
+var DiffTemplate *template.Template
+
+type DiffData struct {
+	Title        string
+	From         DiffSide
+	To           DiffSide
+	FieldChanges []FieldChange
+	SourceDiff   []DiffSegment
+	TextDiff     []DiffSegment
+}
+
+var editTemplateContent = `{{ if .Error }}<p class="error">{{ .Error }}</p>{{ end }}
+<form method="post" action="/{{ .Title }}/edit" class="edit-form">
+	<input type="hidden" name="_csrf" value="{{ .CSRFToken }}">
+	<input type="hidden" name="base" value="{{ .Base }}">
+	<textarea name="content" rows="30">
+{{ .Source }}</textarea>
+	<p>
+		<button type="submit">Save</button>
+		<a href="/{{ .Title }}">Cancel</a>
+	</p>
+</form>`
+
+var EditTemplate *template.Template
+
+var draftPreviewTemplateContent = `<p>
+	{{ if .Previewing }}Hidden pages, such as drafts and scheduled pages, are shown to you.
+	{{ else }}Hidden pages, such as drafts and scheduled pages, are only shown to you while previewing drafts.{{ end }}
+</p>
+<form method="post" action="{{ .Action }}">
+	<input type="hidden" name="_csrf" value="{{ .CSRFToken }}">
+	<input type="hidden" name="show" value="{{ not .Previewing }}">
+	<input type="hidden" name="return" value="{{ .Return }}">
+	<p>
+		<button type="submit">{{ if .Previewing }}Hide drafts{{ else }}Show drafts{{ end }}</button>
+		<a href="{{ .Return }}">Cancel</a>
+	</p>
+</form>`
+
+var DraftPreviewTemplate *template.Template
+
+var createTemplateContent = `<p>Looks like this page doesn't exist yet.</p>
+<form method="get" action="/{{ .Title }}/edit">
+	<label>
+		Start from
+		<select name="category">
+			<option value="">a blank page</option>
+			{{ range .Categories }}<option value="{{ . }}">a page in {{ . }}</option>
+			{{ end }}
+		</select>
+	</label>
+	<button type="submit">Create page</button>
+</form>`
+
+var CreateTemplate *template.Template
+
+type CreateData struct {
+	Title      string
+	Categories []string
+}
+
+type DraftPreviewData struct {
+	// Action is the path the form is posted to.
+	Action     string
+	Return     string
+	Previewing bool
+	CSRFToken  string
+}
+
+type EditData struct {
+	Title     string
+	Source    string
+	Base      string
+	CSRFToken string
+	Error     string
+}
+
 func initTemplate(name string, content string) *template.Template {
 	t, err := template.New(name).Parse(content)
 	if err != nil {
@@ -100,4 +565,14 @@ func initTemplate(name string, content string) *template.Template {
 func init() {
 	PageTemplate = initTemplate("page", pageTemplateContent)
 	LinkListingTemplate = initTemplate("linkListing", linkListingTemplateContent)
+	CategoryTemplate = initTemplate("category", categoryTemplateContent)
+	CategoriesTemplate = initTemplate("categories", categoriesTemplateContent)
+	AllPagesTemplate = initTemplate("allPages", allPagesTemplateContent)
+	OnThisDayTemplate = initTemplate("onThisDay", onThisDayTemplateContent)
+	RecentChangesTemplate = initTemplate("recentChanges", recentChangesTemplateContent)
+	HistoryTemplate = initTemplate("history", historyTemplateContent)
+	DiffTemplate = initTemplate("diff", diffTemplateContent)
+	EditTemplate = initTemplate("edit", editTemplateContent)
+	DraftPreviewTemplate = initTemplate("draftPreview", draftPreviewTemplateContent)
+	CreateTemplate = initTemplate("create", createTemplateContent)
 }
@@ -0,0 +1,53 @@
+package content
+
+import (
+	"bytes"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+)
+
+var blockElements = map[string]struct{}{
+	"blockquote": {}, "br": {}, "dd": {}, "div": {}, "dt": {}, "h1": {}, "h2": {}, "h3": {},
+	"h4": {}, "h5": {}, "h6": {}, "hr": {}, "li": {}, "p": {}, "pre": {}, "section": {},
+	"td": {}, "th": {}, "tr": {},
+}
+
+// PlainText strips all markup from rendered page content, collapsing whitespace.
+func PlainText(content []byte) string {
+	tokenizer := html.NewTokenizer(bytes.NewReader(content))
+
+	var builder strings.Builder
+
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			return strings.Join(strings.Fields(builder.String()), " ")
+		case html.TextToken:
+			builder.Write(tokenizer.Text())
+		case html.StartTagToken, html.EndTagToken, html.SelfClosingTagToken:
+			name, _ := tokenizer.TagName()
+			if _, ok := blockElements[string(name)]; ok {
+				builder.WriteByte(' ')
+			}
+		case html.CommentToken, html.DoctypeToken:
+		}
+	}
+}
+
+// Excerpt returns the start of the page's plain text, cut at a word boundary
+// so that it is at most maxLength characters long.
+func Excerpt(page *Page, maxLength int) string {
+	text := PlainText(page.ParsedContent)
+	if utf8.RuneCountInString(text) <= maxLength {
+		return text
+	}
+
+	runes := []rune(text)[:maxLength]
+	if i := strings.LastIndex(string(runes), " "); i > 0 {
+		return string(runes)[:i] + "…"
+	}
+
+	return string(runes) + "…"
+}
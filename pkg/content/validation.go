@@ -0,0 +1,297 @@
+package content
+
+import (
+	"fmt"
+	"log/slog"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	FieldTypeString    = "string"
+	FieldTypeDate      = "date"
+	FieldTypePage      = "page"
+	FieldTypeDiscordID = "discord_id"
+	FieldTypeURL       = "url"
+	FieldTypeEnum      = "enum"
+)
+
+var fieldTypes = []string{FieldTypeString, FieldTypeDate, FieldTypePage, FieldTypeDiscordID, FieldTypeURL, FieldTypeEnum}
+
+// FieldSchema declares a frontmatter field of the pages in a category.
+type FieldSchema struct {
+	// Type is one of "string", "date", "page" (the title of an existing page),
+	// "discord_id", "url" or "enum". Lists are validated item by item.
+	Type     string `mapstructure:"type"`
+	Required bool   `mapstructure:"required"`
+	// Values are the values allowed for enum fields.
+	Values []string `mapstructure:"values"`
+}
+
+// Validate checks that the schema's type is known and that enums list their
+// values.
+func (s FieldSchema) Validate() error {
+	for _, fieldType := range fieldTypes {
+		if s.Type == fieldType {
+			if s.Type == FieldTypeEnum && len(s.Values) == 0 {
+				return fmt.Errorf("enum fields must list their allowed values")
+			}
+			return nil
+		}
+	}
+
+	return fmt.Errorf("unknown field type %q, must be one of %s", s.Type, strings.Join(fieldTypes, ", "))
+}
+
+// ValidationIssue is a problem with a page's frontmatter.
+type ValidationIssue struct {
+	Page    string
+	Field   string
+	Message string
+}
+
+func (i ValidationIssue) String() string {
+	return fmt.Sprintf("%s: %s: %s", i.Page, i.Field, i.Message)
+}
+
+var discordIDPattern = regexp.MustCompile(`^[0-9]{17,20}$`)
+
+// validateFieldValue checks a single frontmatter value against a schema,
+// returning a description of the problem if it is invalid.
+func validateFieldValue(pages map[string]*Page, schema FieldSchema, value interface{}) string {
+	if list, ok := value.([]interface{}); ok {
+		for _, item := range list {
+			if problem := validateFieldValue(pages, schema, item); problem != "" {
+				return problem
+			}
+		}
+		return ""
+	}
+
+	switch schema.Type {
+	case FieldTypeDate:
+		switch v := value.(type) {
+		case time.Time, int64:
+			return ""
+		case string:
+			if _, err := ParseDate(v); err != nil {
+				return err.Error()
+			}
+			return ""
+		}
+		return fmt.Sprintf("expected a date, got %v", value)
+	case FieldTypeDiscordID:
+		if v, ok := value.(int64); ok {
+			value = fmt.Sprint(v)
+		}
+		if v, ok := value.(string); !ok || !discordIDPattern.MatchString(v) {
+			return fmt.Sprintf("expected a Discord ID, got %v", value)
+		}
+		return ""
+	}
+
+	v, ok := value.(string)
+	if !ok {
+		return fmt.Sprintf("expected a string, got %v", value)
+	}
+
+	switch schema.Type {
+	case FieldTypePage:
+		if _, ok := pages[v]; !ok {
+			return fmt.Sprintf("page %q doesn't exist", v)
+		}
+	case FieldTypeURL:
+		u, err := url.Parse(v)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Sprintf("expected an http or https URL, got %q", v)
+		}
+	case FieldTypeEnum:
+		for _, allowed := range schema.Values {
+			if v == allowed {
+				return ""
+			}
+		}
+		return fmt.Sprintf("%q is not one of %s", v, strings.Join(schema.Values, ", "))
+	}
+
+	return ""
+}
+
+// editDistance returns the Levenshtein distance between two strings.
+func editDistance(a string, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+
+	return previous[len(b)]
+}
+
+// suggestField returns the known field closest to an unknown one, if it's
+// close enough to likely be a typo. Short fields must be closer, as most short
+// names are a couple of edits from a known field.
+func suggestField(field string, known []string) (string, bool) {
+	best, bestDistance := "", 3
+	if len(field) <= 4 {
+		bestDistance = 2
+	}
+	for _, candidate := range known {
+		normalized := strings.ReplaceAll(candidate, "_", "")
+		distance := min(editDistance(field, candidate), editDistance(strings.ReplaceAll(field, "_", ""), normalized))
+		if distance < bestDistance {
+			best, bestDistance = candidate, distance
+		}
+	}
+	return best, best != ""
+}
+
+// fieldSchemas merges the field schemas of categories.
+func (p *Parser) fieldSchemas(categories []string) map[string]FieldSchema {
+	schemas := make(map[string]FieldSchema)
+
+	for _, category := range categories {
+		for field, schema := range p.CategoryConfig(category).Fields {
+			schemas[field] = schema
+		}
+	}
+
+	return schemas
+}
+
+// ValidatePage checks a content page's frontmatter against the schemas of its
+// categories, and looks for misspellings of known fields.
+func (p *Parser) ValidatePage(pages map[string]*Page, page *Page) []ValidationIssue {
+	issues := make([]ValidationIssue, 0)
+
+	// Description pages aren't members of their categories, so only their
+	// field names are checked.
+	categories := page.Categories()
+	if page.IsCategoryDescription() {
+		categories = nil
+	}
+
+	schemas := p.fieldSchemas(categories)
+	strict := false
+	infoboxFields := make([]string, 0)
+
+	for _, category := range categories {
+		config := p.CategoryConfig(category)
+		for _, field := range config.Infobox {
+			infoboxFields = append(infoboxFields, field.Field)
+		}
+		strict = strict || config.Strict
+	}
+
+	known := append(append([]string{}, builtinFields...), infoboxFields...)
+	for field := range schemas {
+		known = append(known, field)
+	}
+
+	fields := make([]string, 0, len(schemas))
+	for field := range schemas {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	for _, field := range fields {
+		schema := schemas[field]
+
+		value, ok := page.fields[field]
+		if !ok || value == "" {
+			if schema.Required {
+				issues = append(issues, ValidationIssue{Page: page.Title, Field: field, Message: "is required"})
+			}
+			continue
+		}
+
+		if problem := validateFieldValue(pages, schema, value); problem != "" {
+			issues = append(issues, ValidationIssue{Page: page.Title, Field: field, Message: problem})
+		}
+	}
+
+	unknown := make([]string, 0)
+	for field := range page.fields {
+		isKnown := false
+		for _, k := range known {
+			if field == k {
+				isKnown = true
+				break
+			}
+		}
+		if !isKnown {
+			unknown = append(unknown, field)
+		}
+	}
+	sort.Strings(unknown)
+
+	for _, field := range unknown {
+		if suggestion, ok := suggestField(field, known); ok {
+			issues = append(issues, ValidationIssue{
+				Page:    page.Title,
+				Field:   field,
+				Message: fmt.Sprintf("unknown field, did you mean %q?", suggestion),
+			})
+		} else if strict {
+			issues = append(issues, ValidationIssue{Page: page.Title, Field: field, Message: "unknown field"})
+		}
+	}
+
+	return issues
+}
+
+// ValidatePages validates every content page, returning issues sorted by page.
+func (p *Parser) ValidatePages(pages map[string]*Page) []ValidationIssue {
+	issues := make([]ValidationIssue, 0)
+
+	for _, title := range p.AllPageTitles(pages) {
+		page := pages[title]
+		if page.Path == nil {
+			continue
+		}
+		issues = append(issues, p.ValidatePage(pages, page)...)
+	}
+
+	return issues
+}
+
+// reportedIssues are the issues found by the last discovery of pages, so that
+// discovering pages on every request only logs each issue once. Fixed issues
+// are forgotten, and logged again if they reappear.
+var reportedIssues = struct {
+	sync.Mutex
+	logged map[ValidationIssue]bool
+}{logged: make(map[ValidationIssue]bool)}
+
+// reportIssues logs the issues that weren't found by the last discovery.
+func reportIssues(issues []ValidationIssue) {
+	reportedIssues.Lock()
+	defer reportedIssues.Unlock()
+
+	current := make(map[ValidationIssue]bool, len(issues))
+	for _, issue := range issues {
+		current[issue] = true
+		if reportedIssues.logged[issue] {
+			continue
+		}
+		slog.Warn("Invalid frontmatter", "page", issue.Page, "field", issue.Field, "problem", issue.Message)
+	}
+
+	reportedIssues.logged = current
+}
@@ -0,0 +1,197 @@
+package content
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"go.abhg.dev/goldmark/frontmatter"
+	"gopkg.in/yaml.v3"
+)
+
+// tomlLocalDate, tomlLocalDatetime and tomlLocalTime are the locations TOML
+// local dates and times are decoded in. Frontmatter code tells them apart by
+// location, so YAML values use the same ones.
+var tomlLocalDate, tomlLocalDatetime, tomlLocalTime = func() (*time.Location, *time.Location, *time.Location) {
+	var values map[string]interface{}
+	_, err := toml.Decode("date = 2000-01-01\ndatetime = 2000-01-01T00:00:00\ntime = 00:00:00", &values)
+	if err != nil {
+		panic(err)
+	}
+
+	return values["date"].(time.Time).Location(),
+		values["datetime"].(time.Time).Location(),
+		values["time"].(time.Time).Location()
+}()
+
+// yamlLayout is a layout of a YAML value decoded as a TOML local date or
+// time, and the location TOML decodes it in.
+type yamlLayout struct {
+	layout   string
+	location *time.Location
+}
+
+// yamlTimestampLayouts are the YAML timestamps without a timezone, which are
+// decoded like TOML's local dates and date-times.
+var yamlTimestampLayouts = []yamlLayout{
+	{"2006-01-02", tomlLocalDate},
+	{"2006-01-02T15:04:05.999999999", tomlLocalDatetime},
+	{"2006-01-02t15:04:05.999999999", tomlLocalDatetime},
+	{"2006-01-02 15:04:05.999999999", tomlLocalDatetime},
+}
+
+// yamlPlainLayouts are the TOML local date-times and times that YAML doesn't
+// recognise as timestamps. Unquoted strings in these layouts are decoded as
+// TOML would decode them.
+var yamlPlainLayouts = []yamlLayout{
+	{"2006-01-02T15:04:05.999999999", tomlLocalDatetime},
+	{"2006-01-02t15:04:05.999999999", tomlLocalDatetime},
+	{"15:04:05.999999999", tomlLocalTime},
+}
+
+// yamlLocalTime parses a value in one of layouts as a time in the TOML local
+// location of the layout.
+func yamlLocalTime(value string, layouts []yamlLayout) (time.Time, bool) {
+	for _, layout := range layouts {
+		if t, err := time.ParseInLocation(layout.layout, value, time.UTC); err == nil {
+			return time.Date(
+				t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(),
+				layout.location,
+			), true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+// yamlScalar decodes a YAML scalar to the value TOML would give it: int64s,
+// float64s, bools, strings, and time.Times in the TOML local locations.
+func yamlScalar(node *yaml.Node) (interface{}, error) {
+	switch node.ShortTag() {
+	case "!!int":
+		var i int64
+		err := node.Decode(&i)
+		return i, err
+	case "!!float":
+		var f float64
+		err := node.Decode(&f)
+		return f, err
+	case "!!bool":
+		var b bool
+		err := node.Decode(&b)
+		return b, err
+	case "!!null":
+		return nil, nil
+	case "!!timestamp":
+		if t, ok := yamlLocalTime(node.Value, yamlTimestampLayouts); ok {
+			return t, nil
+		}
+
+		var t time.Time
+		err := node.Decode(&t)
+		return t, err
+	case "!!str":
+		// Quoted and explicitly tagged strings are always strings.
+		if node.Style == 0 {
+			if t, ok := yamlLocalTime(node.Value, yamlPlainLayouts); ok {
+				return t, nil
+			}
+		}
+	}
+
+	return node.Value, nil
+}
+
+// yamlValue decodes YAML to the values TOML would give it, so that YAML
+// frontmatter has the same semantics as TOML frontmatter. Null values, which
+// TOML doesn't have, are left out of mappings and sequences.
+func yamlValue(node *yaml.Node) (interface{}, error) {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		if len(node.Content) == 0 {
+			return map[string]interface{}{}, nil
+		}
+		return yamlValue(node.Content[0])
+	case yaml.AliasNode:
+		return yamlValue(node.Alias)
+	case yaml.MappingNode:
+		mapping := make(map[string]interface{}, len(node.Content)/2)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			value, err := yamlValue(node.Content[i+1])
+			if err != nil {
+				return nil, err
+			}
+			if value != nil {
+				mapping[node.Content[i].Value] = value
+			}
+		}
+		return mapping, nil
+	case yaml.SequenceNode:
+		list := make([]interface{}, 0, len(node.Content))
+		for _, item := range node.Content {
+			value, err := yamlValue(item)
+			if err != nil {
+				return nil, err
+			}
+			if value != nil {
+				list = append(list, value)
+			}
+		}
+		return list, nil
+	}
+
+	return yamlScalar(node)
+}
+
+// unmarshalYAMLFrontmatter decodes YAML frontmatter. Raw fields are decoded
+// to the same types as TOML frontmatter; structs use their yaml tags.
+func unmarshalYAMLFrontmatter(data []byte, v interface{}) error {
+	fields, ok := v.(*map[string]interface{})
+	if !ok {
+		return yaml.Unmarshal(data, v)
+	}
+
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return err
+	}
+
+	value, err := yamlValue(&node)
+	if err != nil {
+		return err
+	}
+
+	mapping, ok := value.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("frontmatter must be a mapping")
+	}
+
+	*fields = mapping
+	return nil
+}
+
+// frontmatterFormats are the frontmatter formats pages may use: TOML between
+// "+++" lines, or YAML between "---" lines.
+var frontmatterFormats = []frontmatter.Format{
+	frontmatter.TOML,
+	{
+		Name:      "YAML",
+		Delim:     '-',
+		Unmarshal: unmarshalYAMLFrontmatter,
+	},
+}
+
+// UnmarshalYAML decodes a date from YAML frontmatter, accepting the same forms
+// as in TOML frontmatter.
+func (d *Date) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.ScalarNode {
+		return fmt.Errorf("invalid date %v", node.Value)
+	}
+
+	value, err := yamlScalar(node)
+	if err != nil {
+		return err
+	}
+
+	return d.UnmarshalTOML(value)
+}
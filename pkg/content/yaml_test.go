@@ -0,0 +1,92 @@
+package content
+
+import (
+	"reflect"
+	"testing"
+)
+
+// frontmatterPairs are equivalent frontmatter written in TOML and YAML.
+var frontmatterPairs = []struct {
+	name string
+	toml string
+	yaml string
+}{
+	{
+		name: "meta",
+		toml: "title = \"Inn\"\ncategories = [\"Events\", \"Places\"]\ndate = 2024-03-06\nend_date = \"2024-03\"\nredirect = \"Fogo Island Inn\"\n",
+		yaml: "title: Inn\ncategories: [Events, Places]\ndate: 2024-03-06\nend_date: 2024-03\nredirect: Fogo Island Inn\n",
+	},
+	{
+		name: "scalars",
+		toml: "name = \"Riley\"\ncount = 3\nweight = 1.5\nactive = true\nquoted = \"2024-03-06T10:00:00\"\n",
+		yaml: "name: Riley\ncount: 3\nweight: 1.5\nactive: true\nquoted: \"2024-03-06T10:00:00\"\n",
+	},
+	{
+		name: "local dates and times",
+		toml: "day = 2024-03-06\nat = 2024-03-06T10:00:00\nspaced = 2024-03-06 10:00:00.5\nclock = 10:00:00\n",
+		yaml: "day: 2024-03-06\nat: 2024-03-06T10:00:00\nspaced: 2024-03-06 10:00:00.5\nclock: 10:00:00\n",
+	},
+	{
+		name: "offset date-time",
+		toml: "at = 2024-03-06T10:00:00-03:30\n",
+		yaml: "at: 2024-03-06T10:00:00-03:30\n",
+	},
+	{
+		name: "collections",
+		toml: "attendees = [\"Jack\", \"Riley\"]\nscores = [1, 2]\n[venue]\nname = \"Inn\"\nopened = 2013-05-01\n",
+		yaml: "attendees: [Jack, Riley]\nscores: [1, null, 2]\nmissing: null\nvenue:\n  name: Inn\n  opened: 2013-05-01\n",
+	},
+}
+
+func parseFrontmatter(t *testing.T, source string) Page {
+	t.Helper()
+
+	page, err := (&Parser{}).ParsePage("Test.md", []byte(source), nil)
+	if err != nil {
+		t.Fatalf("failed to parse %q: %v", source, err)
+	}
+
+	return page
+}
+
+func TestYAMLFrontmatterMatchesTOML(t *testing.T) {
+	for _, pair := range frontmatterPairs {
+		t.Run(pair.name, func(t *testing.T) {
+			tomlPage := parseFrontmatter(t, "+++\n"+pair.toml+"+++\n")
+			yamlPage := parseFrontmatter(t, "---\n"+pair.yaml+"---\n")
+
+			if !reflect.DeepEqual(tomlPage.fields, yamlPage.fields) {
+				t.Errorf("fields differ:\nTOML: %#v\nYAML: %#v", tomlPage.fields, yamlPage.fields)
+			}
+			if !reflect.DeepEqual(tomlPage.Meta, yamlPage.Meta) {
+				t.Errorf("meta differs:\nTOML: %#v\nYAML: %#v", tomlPage.Meta, yamlPage.Meta)
+			}
+		})
+	}
+}
+
+func TestConvertFrontmatterRoundTrip(t *testing.T) {
+	for _, pair := range frontmatterPairs {
+		t.Run(pair.name, func(t *testing.T) {
+			source := []byte("+++\n" + pair.toml + "+++\nBody\n")
+
+			yamlSource, ok, err := ConvertFrontmatter(source, FrontmatterYAML)
+			if err != nil || !ok {
+				t.Fatalf("failed to convert to YAML: %v", err)
+			}
+
+			tomlSource, ok, err := ConvertFrontmatter(yamlSource, FrontmatterTOML)
+			if err != nil || !ok {
+				t.Fatalf("failed to convert back to TOML: %v", err)
+			}
+
+			original := parseFrontmatter(t, string(source))
+			for _, converted := range [][]byte{yamlSource, tomlSource} {
+				page := parseFrontmatter(t, string(converted))
+				if !reflect.DeepEqual(original.fields, page.fields) {
+					t.Errorf("fields differ after conversion:\n%s\nwant: %#v\ngot:  %#v", converted, original.fields, page.fields)
+				}
+			}
+		})
+	}
+}
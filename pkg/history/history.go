@@ -0,0 +1,571 @@
+package history
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+var ErrNotRepository = errors.New("not a git repository")
+
+type Commit struct {
+	Hash    string
+	Author  string
+	Email   string
+	Time    time.Time
+	Message string
+}
+
+// Summary returns the first line of the commit message.
+func (c Commit) Summary() string {
+	summary, _, _ := strings.Cut(strings.TrimSpace(c.Message), "\n")
+	return summary
+}
+
+// ShortHash returns the abbreviated commit hash.
+func (c Commit) ShortHash() string {
+	if len(c.Hash) < 7 {
+		return c.Hash
+	}
+	return c.Hash[:7]
+}
+
+// Change is a commit that modified a file in the content directory.
+type Change struct {
+	Commit
+
+	// Path is the file path relative to the content directory.
+	Path string
+	// Size is the file size in bytes after the change.
+	Size int64
+	// SizeDelta is the change in the file's size in bytes.
+	SizeDelta int64
+
+	// blob is the file's blob after the change, if known.
+	blob plumbing.Hash
+}
+
+// Matches reports whether content is the file as it was after the change. It
+// is only known for changes returned by LastChanges, and is false otherwise.
+func (c Change) Matches(content []byte) bool {
+	return !c.blob.IsZero() && plumbing.ComputeHash(plumbing.BlobObject, content) == c.blob
+}
+
+// Repository gives access to the history of a content directory that is
+// part of a git repository.
+type Repository struct {
+	repo *git.Repository
+	root string
+	// prefix is the slash-separated path of the content directory relative to
+	// the repository root, or "" if they're the same.
+	prefix string
+}
+
+// Open opens the git repository that contains contentDir. If contentDir is not
+// in a repository, ErrNotRepository is returned.
+func Open(contentDir string) (*Repository, error) {
+	repo, err := git.PlainOpenWithOptions(contentDir, &git.PlainOpenOptions{DetectDotGit: true})
+	if errors.Is(err, git.ErrRepositoryNotExists) {
+		return nil, ErrNotRepository
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open repository: %w", err)
+	}
+
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open worktree: %w", err)
+	}
+
+	root, err := filepath.EvalSymlinks(worktree.Filesystem.Root())
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve repository root: %w", err)
+	}
+
+	dir, err := filepath.Abs(contentDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve content directory: %w", err)
+	}
+
+	dir, err = filepath.EvalSymlinks(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve content directory: %w", err)
+	}
+
+	prefix, err := filepath.Rel(root, dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find content directory in repository: %w", err)
+	}
+
+	prefix = filepath.ToSlash(prefix)
+	if prefix == "." {
+		prefix = ""
+	}
+
+	return &Repository{repo: repo, root: root, prefix: prefix}, nil
+}
+
+// contentPath converts a path relative to the repository root to a path
+// relative to the content directory, reporting whether it is in the content
+// directory at all.
+func (r *Repository) contentPath(name string) (string, bool) {
+	if r.prefix == "" {
+		return name, true
+	}
+
+	return strings.CutPrefix(name, r.prefix+"/")
+}
+
+func newCommit(commit *object.Commit) Commit {
+	return Commit{
+		Hash:    commit.Hash.String(),
+		Author:  commit.Author.Name,
+		Email:   commit.Author.Email,
+		Time:    commit.Author.When,
+		Message: commit.Message,
+	}
+}
+
+var errNoCommits = errors.New("repository has no commits")
+
+func (r *Repository) head() (*object.Commit, error) {
+	ref, err := r.repo.Head()
+	if errors.Is(err, plumbing.ErrReferenceNotFound) {
+		return nil, errNoCommits
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve HEAD: %w", err)
+	}
+
+	commit, err := r.repo.CommitObject(ref.Hash())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read HEAD commit: %w", err)
+	}
+
+	return commit, nil
+}
+
+func (r *Repository) blobSize(hash plumbing.Hash) int64 {
+	if hash.IsZero() {
+		return 0
+	}
+
+	blob, err := r.repo.BlobObject(hash)
+	if err != nil {
+		return 0
+	}
+
+	return blob.Size
+}
+
+// changesInCommit returns the changes a commit made relative to its first
+// parent, with renames detected.
+func changesInCommit(commit *object.Commit) (object.Changes, error) {
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tree of %s: %w", commit.Hash, err)
+	}
+
+	var parentTree *object.Tree
+	if commit.NumParents() > 0 {
+		parent, err := commit.Parent(0)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read parent of %s: %w", commit.Hash, err)
+		}
+
+		parentTree, err = parent.Tree()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read tree of %s: %w", parent.Hash, err)
+		}
+	}
+
+	changes, err := object.DiffTreeWithOptions(
+		context.Background(),
+		parentTree,
+		tree,
+		&object.DiffTreeOptions{DetectRenames: true, RenameScore: 60, RenameLimit: 100},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to diff %s: %w", commit.Hash, err)
+	}
+
+	return changes, nil
+}
+
+type lastChangesCacheEntry struct {
+	head    plumbing.Hash
+	changes map[string]Change
+}
+
+var (
+	lastChangesCacheLock sync.Mutex
+	lastChangesCache     = map[string]lastChangesCacheEntry{}
+)
+
+// LastChanges returns the most recent change to every file in the content
+// directory that exists at HEAD, keyed by path relative to the content
+// directory.
+func (r *Repository) LastChanges() (map[string]Change, error) {
+	head, err := r.head()
+	if errors.Is(err, errNoCommits) {
+		return map[string]Change{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	cacheKey := r.root + ":" + r.prefix
+	lastChangesCacheLock.Lock()
+	cached, ok := lastChangesCache[cacheKey]
+	lastChangesCacheLock.Unlock()
+	if ok && cached.head == head.Hash {
+		return cached.changes, nil
+	}
+
+	remaining := make(map[string]struct{})
+
+	files, err := head.Files()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files: %w", err)
+	}
+
+	err = files.ForEach(func(file *object.File) error {
+		if name, ok := r.contentPath(file.Name); ok {
+			remaining[name] = struct{}{}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files: %w", err)
+	}
+
+	lastChanges := make(map[string]Change, len(remaining))
+
+	commits, err := r.repo.Log(&git.LogOptions{From: head.Hash, Order: git.LogOrderCommitterTime})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read log: %w", err)
+	}
+	defer commits.Close()
+
+	for len(remaining) > 0 {
+		commit, err := commits.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read log: %w", err)
+		}
+
+		changes, err := changesInCommit(commit)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, change := range changes {
+			name, ok := r.contentPath(change.To.Name)
+			if !ok {
+				continue
+			}
+
+			if _, ok := remaining[name]; !ok {
+				continue
+			}
+
+			size := r.blobSize(change.To.TreeEntry.Hash)
+			lastChanges[name] = Change{
+				Commit:    newCommit(commit),
+				Path:      name,
+				Size:      size,
+				SizeDelta: size - r.blobSize(change.From.TreeEntry.Hash),
+				blob:      change.To.TreeEntry.Hash,
+			}
+			delete(remaining, name)
+		}
+	}
+
+	lastChangesCacheLock.Lock()
+	lastChangesCache[cacheKey] = lastChangesCacheEntry{head: head.Hash, changes: lastChanges}
+	lastChangesCacheLock.Unlock()
+
+	return lastChanges, nil
+}
+
+var ErrRevisionNotFound = errors.New("revision not found")
+
+// repoPath converts a path relative to the content directory to a path
+// relative to the repository root.
+func (r *Repository) repoPath(name string) string {
+	return path.Join(r.prefix, filepath.ToSlash(name))
+}
+
+// renamedFrom finds the path a file was renamed from between two trees, or
+// returns "" if it was newly added.
+func renamedFrom(from *object.Tree, to *object.Tree, name string) (string, error) {
+	changes, err := object.DiffTreeWithOptions(
+		context.Background(),
+		from,
+		to,
+		&object.DiffTreeOptions{DetectRenames: true, RenameScore: 60, RenameLimit: 100},
+	)
+	if err != nil {
+		return "", fmt.Errorf("failed to diff trees: %w", err)
+	}
+
+	for _, change := range changes {
+		if change.To.Name == name && change.From.Name != "" && change.From.Name != name {
+			return change.From.Name, nil
+		}
+	}
+
+	return "", nil
+}
+
+// FileHistory returns the commits that changed a file, newest first, along
+// the first-parent history of HEAD. Renames are followed, so the history
+// includes changes made under previous names of the file.
+func (r *Repository) FileHistory(name string) ([]Change, error) {
+	commit, err := r.head()
+	if errors.Is(err, errNoCommits) {
+		return []Change{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make([]Change, 0)
+	current := r.repoPath(name)
+
+	for {
+		tree, err := commit.Tree()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read tree of %s: %w", commit.Hash, err)
+		}
+
+		entry, err := tree.FindEntry(current)
+		if errors.Is(err, object.ErrEntryNotFound) || errors.Is(err, object.ErrDirectoryNotFound) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to find %s in %s: %w", current, commit.Hash, err)
+		}
+
+		var parent *object.Commit
+		var parentTree *object.Tree
+		var parentEntry *object.TreeEntry
+
+		if commit.NumParents() > 0 {
+			parent, err = commit.Parent(0)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read parent of %s: %w", commit.Hash, err)
+			}
+
+			parentTree, err = parent.Tree()
+			if err != nil {
+				return nil, fmt.Errorf("failed to read tree of %s: %w", parent.Hash, err)
+			}
+
+			parentEntry, _ = parentTree.FindEntry(current)
+		}
+
+		if parentEntry != nil && parentEntry.Hash == entry.Hash {
+			commit = parent
+			continue
+		}
+
+		previous := current
+		if parentEntry == nil && parentTree != nil {
+			previous, err = renamedFrom(parentTree, tree, current)
+			if err != nil {
+				return nil, err
+			}
+
+			if _, ok := r.contentPath(previous); !ok {
+				previous = ""
+			}
+
+			if previous != "" {
+				parentEntry, _ = parentTree.FindEntry(previous)
+			}
+		}
+
+		contentPath, _ := r.contentPath(current)
+		size := r.blobSize(entry.Hash)
+		change := Change{
+			Commit:    newCommit(commit),
+			Path:      contentPath,
+			Size:      size,
+			SizeDelta: size,
+		}
+		if parentEntry != nil {
+			change.SizeDelta = size - r.blobSize(parentEntry.Hash)
+		}
+		changes = append(changes, change)
+
+		if parent == nil || previous == "" {
+			break
+		}
+
+		current = previous
+		commit = parent
+	}
+
+	return changes, nil
+}
+
+// FileAt returns the contents of a file, given by its path relative to the
+// content directory, as of the given commit.
+func (r *Repository) FileAt(hash string, name string) ([]byte, error) {
+	commit, err := r.repo.CommitObject(plumbing.NewHash(hash))
+	if errors.Is(err, plumbing.ErrObjectNotFound) {
+		return nil, ErrRevisionNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read commit %s: %w", hash, err)
+	}
+
+	file, err := commit.File(r.repoPath(name))
+	if errors.Is(err, object.ErrFileNotFound) {
+		return nil, ErrRevisionNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s at %s: %w", name, hash, err)
+	}
+
+	contents, err := file.Contents()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s at %s: %w", name, hash, err)
+	}
+
+	return []byte(contents), nil
+}
+
+// FindChange finds the change to a file made by the commit with the given
+// hash or hash prefix, returning ErrRevisionNotFound if there is none.
+func FindChange(changes []Change, revision string) (Change, error) {
+	if len(revision) < 4 {
+		return Change{}, ErrRevisionNotFound
+	}
+
+	for _, change := range changes {
+		if strings.HasPrefix(change.Hash, revision) {
+			return change, nil
+		}
+	}
+
+	return Change{}, ErrRevisionNotFound
+}
+
+// AuthorLines is the number of lines of a file last changed by an author.
+type AuthorLines struct {
+	Author string
+	Email  string
+	Lines  int
+}
+
+// Authorship summarises who wrote a file.
+type Authorship struct {
+	// Authors lists every author with lines in the file at HEAD, most lines
+	// first.
+	Authors []AuthorLines
+	// FirstChange is the commit that created the file, following renames.
+	FirstChange Change
+	// LastChange is the most recent commit to the file.
+	LastChange Change
+}
+
+type authorshipCacheEntry struct {
+	head       plumbing.Hash
+	authorship *Authorship
+}
+
+var (
+	authorshipCacheLock sync.Mutex
+	authorshipCache     = map[string]authorshipCacheEntry{}
+)
+
+// Authorship computes authorship statistics for a file, given by its path
+// relative to the content directory, from git blame. It returns nil if the file
+// hasn't been committed. Results are cached until HEAD changes.
+func (r *Repository) Authorship(name string) (*Authorship, error) {
+	head, err := r.head()
+	if errors.Is(err, errNoCommits) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	cacheKey := r.root + ":" + r.repoPath(name)
+	authorshipCacheLock.Lock()
+	cached, ok := authorshipCache[cacheKey]
+	authorshipCacheLock.Unlock()
+	if ok && cached.head == head.Hash {
+		return cached.authorship, nil
+	}
+
+	authorship, err := r.authorship(head, name)
+	if err != nil {
+		return nil, err
+	}
+
+	authorshipCacheLock.Lock()
+	authorshipCache[cacheKey] = authorshipCacheEntry{head: head.Hash, authorship: authorship}
+	authorshipCacheLock.Unlock()
+
+	return authorship, nil
+}
+
+func (r *Repository) authorship(head *object.Commit, name string) (*Authorship, error) {
+	changes, err := r.FileHistory(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(changes) == 0 {
+		return nil, nil
+	}
+
+	blame, err := git.Blame(head, r.repoPath(name))
+	if err != nil {
+		return nil, fmt.Errorf("failed to blame %s: %w", name, err)
+	}
+
+	linesByEmail := make(map[string]*AuthorLines)
+	authors := make([]*AuthorLines, 0)
+
+	for _, line := range blame.Lines {
+		author, ok := linesByEmail[line.Author]
+		if !ok {
+			author = &AuthorLines{Author: line.AuthorName, Email: line.Author}
+			linesByEmail[line.Author] = author
+			authors = append(authors, author)
+		}
+		author.Lines++
+	}
+
+	sort.SliceStable(authors, func(i, j int) bool {
+		return authors[i].Lines > authors[j].Lines
+	})
+
+	authorship := &Authorship{
+		Authors:     make([]AuthorLines, 0, len(authors)),
+		FirstChange: changes[len(changes)-1],
+		LastChange:  changes[0],
+	}
+	for _, author := range authors {
+		authorship.Authors = append(authorship.Authors, *author)
+	}
+
+	return authorship, nil
+}
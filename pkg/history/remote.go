@@ -0,0 +1,102 @@
+package history
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// RemoteConfig describes a git repository the content directory is cloned
+// from and kept up to date with.
+type RemoteConfig struct {
+	URL string
+	// Branch is the branch to check out, or the remote's default branch if
+	// empty.
+	Branch string
+	// FetchInterval is how often to sync with the remote, or never if zero.
+	FetchInterval time.Duration
+}
+
+func (c RemoteConfig) referenceName() plumbing.ReferenceName {
+	if c.Branch == "" {
+		return ""
+	}
+	return plumbing.NewBranchReferenceName(c.Branch)
+}
+
+// Sync clones the remote into dir if it isn't a repository yet, and updates
+// it to the latest commit on the remote otherwise. The checkout is a mirror of
+// the remote: history rewritten upstream and local changes are discarded. It
+// reports whether the content of dir changed.
+func Sync(remote RemoteConfig, dir string) (bool, error) {
+	repo, err := git.PlainOpen(dir)
+	if errors.Is(err, git.ErrRepositoryNotExists) {
+		_, err = git.PlainClone(dir, false, &git.CloneOptions{
+			URL:           remote.URL,
+			ReferenceName: remote.referenceName(),
+			SingleBranch:  true,
+		})
+		if err != nil {
+			return false, fmt.Errorf("failed to clone %s: %w", remote.URL, err)
+		}
+		return true, nil
+	} else if err != nil {
+		return false, fmt.Errorf("failed to open repository: %w", err)
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		return false, fmt.Errorf("failed to resolve HEAD: %w", err)
+	}
+
+	// Without a configured branch, the branch checked out by the clone is
+	// followed.
+	branch := remote.Branch
+	if branch == "" {
+		branch = head.Name().Short()
+	}
+	remoteRef := plumbing.NewRemoteReferenceName(git.DefaultRemoteName, branch)
+
+	err = repo.Fetch(&git.FetchOptions{
+		RemoteName: git.DefaultRemoteName,
+		RefSpecs: []config.RefSpec{
+			config.RefSpec(fmt.Sprintf("+%s:%s", plumbing.NewBranchReferenceName(branch), remoteRef)),
+		},
+		Force: true,
+	})
+	if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return false, fmt.Errorf("failed to fetch from %s: %w", remote.URL, err)
+	}
+
+	ref, err := repo.Reference(remoteRef, true)
+	if err != nil {
+		return false, fmt.Errorf("failed to resolve %s: %w", remoteRef, err)
+	}
+
+	if ref.Hash() == head.Hash() {
+		return false, nil
+	}
+
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return false, fmt.Errorf("failed to get worktree: %w", err)
+	}
+
+	err = worktree.Reset(&git.ResetOptions{Commit: ref.Hash(), Mode: git.HardReset})
+	if err != nil {
+		return false, fmt.Errorf("failed to reset to %s: %w", remoteRef, err)
+	}
+
+	return true, nil
+}
+
+// IsBranchRef reports whether a ref, such as the one in a push webhook
+// payload, refers to the configured branch. Any branch matches if none is
+// configured.
+func (c RemoteConfig) IsBranchRef(ref string) bool {
+	return c.Branch == "" || plumbing.ReferenceName(ref) == c.referenceName()
+}
@@ -0,0 +1,230 @@
+package server
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+
+	"pkg.fogo.sh/almanac/pkg/content"
+)
+
+const (
+	apiDefaultPerPage = 50
+	apiMaxPerPage     = 500
+	apiMaxPage        = 1000000
+)
+
+type apiPageList struct {
+	Pages      []content.PageDocument `json:"pages"`
+	Page       int                    `json:"page"`
+	PerPage    int                    `json:"per_page"`
+	Total      int                    `json:"total"`
+	TotalPages int                    `json:"total_pages"`
+}
+
+type apiCategory struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// serveAPIData responds with data, tagged with an ETag of its content so that
+// clients can make conditional requests.
+func serveAPIData(c echo.Context, contentType string, data []byte) error {
+	sum := sha256.Sum256(data)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+
+	c.Response().Header().Set("ETag", etag)
+	if c.Request().Header.Get("If-None-Match") == etag {
+		return c.NoContent(http.StatusNotModified)
+	}
+
+	return c.Blob(http.StatusOK, contentType, data)
+}
+
+func serveAPIJSON(c echo.Context, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("error marshalling response: %w", err)
+	}
+
+	return serveAPIData(c, echo.MIMEApplicationJSONCharsetUTF8, data)
+}
+
+// apiPages discovers pages for an API request, checking that the user may
+// view them.
+func (s *Server) apiPages(c echo.Context) (map[string]*content.Page, error) {
+	if !s.isLoggedIn(c) {
+		return nil, echo.NewHTTPError(http.StatusUnauthorized, "You must be logged in to use the API")
+	}
+
+	pages, err := s.discoverPages(c)
+	if err != nil {
+		return nil, fmt.Errorf("error discovering pages: %w", err)
+	}
+
+	return pages, nil
+}
+
+func (s *Server) apiPage(c echo.Context) (*content.Page, error) {
+	pages, err := s.apiPages(c)
+	if err != nil {
+		return nil, err
+	}
+
+	page, ok := pages[c.Param("page")]
+	if !ok {
+		return nil, echo.NewHTTPError(http.StatusNotFound, "Page not found")
+	}
+
+	return page, nil
+}
+
+func queryInt(c echo.Context, name string, fallback int, min int, max int) (int, error) {
+	value := c.QueryParam(name)
+	if value == "" {
+		return fallback, nil
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil || n < min || n > max {
+		return 0, echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid %s: must be between %d and %d", name, min, max))
+	}
+
+	return n, nil
+}
+
+// apiPageFilter reads a page filter from the query parameters category, from,
+// to and redirect. Dates take any form accepted in frontmatter.
+func (s *Server) apiPageFilter(c echo.Context) (content.PageFilter, error) {
+	filter := content.PageFilter{Category: c.QueryParam("category")}
+
+	if from := c.QueryParam("from"); from != "" {
+		date, err := s.parser.ParseLocalDate(from)
+		if err != nil {
+			return filter, echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid from: %v", err))
+		}
+		filter.From = date.Start()
+	}
+
+	if to := c.QueryParam("to"); to != "" {
+		date, err := s.parser.ParseLocalDate(to)
+		if err != nil {
+			return filter, echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid to: %v", err))
+		}
+		filter.To = date.End()
+	}
+
+	if redirect := c.QueryParam("redirect"); redirect != "" {
+		value, err := strconv.ParseBool(redirect)
+		if err != nil {
+			return filter, echo.NewHTTPError(http.StatusBadRequest, "Invalid redirect: must be true or false")
+		}
+		filter.Redirect = &value
+	}
+
+	return filter, nil
+}
+
+func (s *Server) serveAPIPages(c echo.Context) error {
+	pages, err := s.apiPages(c)
+	if err != nil {
+		return err
+	}
+
+	filter, err := s.apiPageFilter(c)
+	if err != nil {
+		return err
+	}
+
+	perPage, err := queryInt(c, "per_page", apiDefaultPerPage, 1, apiMaxPerPage)
+	if err != nil {
+		return err
+	}
+
+	pageNumber, err := queryInt(c, "page", 1, 1, apiMaxPage)
+	if err != nil {
+		return err
+	}
+
+	matching := s.parser.FilterPages(pages, filter)
+
+	list := apiPageList{
+		Pages:      make([]content.PageDocument, 0, perPage),
+		Page:       pageNumber,
+		PerPage:    perPage,
+		Total:      len(matching),
+		TotalPages: (len(matching) + perPage - 1) / perPage,
+	}
+
+	start := (pageNumber - 1) * perPage
+	for i := start; i < len(matching) && i < start+perPage; i++ {
+		list.Pages = append(list.Pages, content.NewPageDocument(matching[i], s.baseURL(c)))
+	}
+
+	return serveAPIJSON(c, list)
+}
+
+func (s *Server) serveAPIPage(c echo.Context) error {
+	page, err := s.apiPage(c)
+	if err != nil {
+		return err
+	}
+
+	return serveAPIJSON(c, content.NewPageDocument(page, s.baseURL(c)))
+}
+
+func (s *Server) serveAPIPageSource(c echo.Context) error {
+	page, err := s.apiPage(c)
+	if err != nil {
+		return err
+	}
+
+	source, err := content.PageSource(page)
+	if errors.Is(err, content.ErrNoSource) {
+		return echo.NewHTTPError(http.StatusNotFound, "Special pages have no source")
+	} else if err != nil {
+		return err
+	}
+
+	return serveAPIData(c, "text/markdown; charset=utf-8", source)
+}
+
+func (s *Server) serveAPIPageHTML(c echo.Context) error {
+	page, err := s.apiPage(c)
+	if err != nil {
+		return err
+	}
+
+	return serveAPIData(c, echo.MIMETextHTMLCharsetUTF8, page.ParsedContent)
+}
+
+func serveAPINotFound(c echo.Context) error {
+	return echo.NewHTTPError(http.StatusNotFound, "Not found")
+}
+
+func (s *Server) serveAPICategories(c echo.Context) error {
+	pages, err := s.apiPages(c)
+	if err != nil {
+		return err
+	}
+
+	pagesByCategory := s.parser.PagesByCategory(pages)
+
+	categories := make([]apiCategory, 0, len(pagesByCategory))
+	for name, categoryPages := range pagesByCategory {
+		categories = append(categories, apiCategory{Name: name, Count: len(categoryPages)})
+	}
+
+	sort.Slice(categories, func(i, j int) bool {
+		return categories[i].Name < categories[j].Name
+	})
+
+	return serveAPIJSON(c, categories)
+}
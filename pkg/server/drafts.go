@@ -0,0 +1,100 @@
+package server
+
+import (
+	"fmt"
+	"html/template"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+
+	"pkg.fogo.sh/almanac/pkg/content"
+)
+
+const draftPreviewPath = "/_almanac/drafts"
+
+// canPreviewDrafts reports whether the user may start a preview session
+// showing hidden pages. This needs Discord OAuth, as otherwise every visitor
+// counts as logged in.
+func (s *Server) canPreviewDrafts(c echo.Context) bool {
+	return s.config.UseDiscordOAuth && s.isLoggedIn(c)
+}
+
+func (s *Server) previewingDrafts(c echo.Context) bool {
+	if !s.canPreviewDrafts(c) {
+		return false
+	}
+
+	previewing, _ := getSession(c).Values["previewDrafts"].(bool)
+	return previewing
+}
+
+// discoverPages discovers pages for a request, including hidden pages for
+// users previewing drafts.
+func (s *Server) discoverPages(c echo.Context) (map[string]*content.Page, error) {
+	if s.parser.IncludeDrafts || !s.previewingDrafts(c) {
+		return s.parser.DiscoverPages(s.config.ContentDir)
+	}
+
+	parser := *s.parser
+	parser.IncludeDrafts = true
+	return parser.DiscoverPages(s.config.ContentDir)
+}
+
+// draftPreviewReturn returns the path to return to after starting or ending a
+// preview session, which must be on this site.
+func draftPreviewReturn(returnTo string) string {
+	if !strings.HasPrefix(returnTo, "/") || strings.HasPrefix(returnTo, "//") || strings.HasPrefix(returnTo, "/\\") {
+		return "/"
+	}
+	return returnTo
+}
+
+// serveDraftPreview offers to start or end a preview session.
+func (s *Server) serveDraftPreview(c echo.Context) error {
+	if !s.canPreviewDrafts(c) {
+		return echo.NewHTTPError(http.StatusForbidden, "You must be logged in with Discord to preview drafts")
+	}
+
+	csrfToken, _ := c.Get(middleware.DefaultCSRFConfig.ContextKey).(string)
+
+	page, err := content.DraftPreviewPage(content.DraftPreviewData{
+		Action:     draftPreviewPath,
+		Return:     draftPreviewReturn(c.QueryParam("return")),
+		Previewing: s.previewingDrafts(c),
+		CSRFToken:  csrfToken,
+	})
+	if err != nil {
+		return fmt.Errorf("error creating page: %w", err)
+	}
+
+	return c.Render(http.StatusOK, "page", content.PageTemplateData{
+		Content: template.HTML(string(page.ParsedContent)),
+		Page:    page,
+		Dates:   s.parser.Dates,
+	})
+}
+
+// saveDraftPreview starts or ends a preview session, then returns to the page
+// the user came from.
+func (s *Server) saveDraftPreview(c echo.Context) error {
+	if !s.canPreviewDrafts(c) {
+		return echo.NewHTTPError(http.StatusForbidden, "You must be logged in with Discord to preview drafts")
+	}
+
+	show, err := strconv.ParseBool(c.FormValue("show"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid show: must be true or false")
+	}
+
+	sess := getSession(c)
+	sess.Values["previewDrafts"] = show
+	err = sess.Save(c.Request(), c.Response())
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to save session: %v", err))
+	}
+
+	return c.Redirect(http.StatusSeeOther, draftPreviewReturn(c.FormValue("return")))
+}
@@ -0,0 +1,135 @@
+package server
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"pkg.fogo.sh/almanac/pkg/history"
+)
+
+const pushHookPath = "/_almanac/hooks/push"
+
+// pullContent clones or pulls the content remote, blocking requests from
+// reading the content directory until it's done.
+func (s *Server) pullContent() error {
+	s.contentLock.Lock()
+	defer s.contentLock.Unlock()
+
+	start := time.Now()
+
+	changed, err := history.Sync(s.config.ContentRemote, s.config.ContentDir)
+	if err != nil {
+		return fmt.Errorf("error syncing content remote: %w", err)
+	}
+
+	if changed {
+		slog.Info("Pulled content from remote", "remote", s.config.ContentRemote.URL, "duration", time.Since(start))
+	}
+
+	return nil
+}
+
+func (s *Server) pollContentRemote() {
+	if s.config.ContentRemote.FetchInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(s.config.ContentRemote.FetchInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		err := s.pullContent()
+		if err != nil {
+			slog.Error("Failed to pull content from remote", "error", err)
+		}
+	}
+}
+
+// readContent holds a read lock on the content directory for the duration of
+// each request, so pulls don't change pages out from under it.
+func (s *Server) readContent(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if c.Path() == pushHookPath {
+			return next(c)
+		}
+
+		s.contentLock.RLock()
+		defer s.contentLock.RUnlock()
+
+		return next(c)
+	}
+}
+
+// validHookSignature checks the HMAC-SHA256 signature of a webhook payload,
+// as sent by GitHub in X-Hub-Signature-256 or by Gitea and Gogs in their own
+// headers.
+func validHookSignature(header http.Header, body []byte, secret string) bool {
+	signature, ok := strings.CutPrefix(header.Get("X-Hub-Signature-256"), "sha256=")
+	if !ok {
+		signature = header.Get("X-Gitea-Signature")
+	}
+	if signature == "" {
+		signature = header.Get("X-Gogs-Signature")
+	}
+
+	received, err := hex.DecodeString(signature)
+	if err != nil || len(received) == 0 {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+
+	return hmac.Equal(received, mac.Sum(nil))
+}
+
+type pushHookPayload struct {
+	Ref string `json:"ref"`
+}
+
+func (s *Server) servePushHook(c echo.Context) error {
+	if s.config.ContentRemote.URL == "" || s.config.WebhookSecret == "" {
+		return serveNotFound(c)
+	}
+
+	body, err := io.ReadAll(c.Request().Body)
+	if err != nil {
+		return fmt.Errorf("error reading request body: %w", err)
+	}
+
+	if !validHookSignature(c.Request().Header, body, s.config.WebhookSecret) {
+		return echo.NewHTTPError(http.StatusUnauthorized, "Invalid webhook signature")
+	}
+
+	for _, eventHeader := range []string{"X-GitHub-Event", "X-Gitea-Event", "X-Gogs-Event"} {
+		if event := c.Request().Header.Get(eventHeader); event != "" && event != "push" {
+			return c.NoContent(http.StatusNoContent)
+		}
+	}
+
+	// Payloads that aren't JSON, such as form-encoded ones, are treated as
+	// pushes to the configured branch.
+	var payload pushHookPayload
+	_ = json.Unmarshal(body, &payload)
+
+	if payload.Ref != "" && !s.config.ContentRemote.IsBranchRef(payload.Ref) {
+		return c.NoContent(http.StatusNoContent)
+	}
+
+	err = s.pullContent()
+	if err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
@@ -5,9 +5,15 @@ import (
 	"fmt"
 	"html/template"
 	"io"
+	"io/fs"
 	"log/slog"
 	"net/http"
+	"net/url"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
 
 	"github.com/bwmarrin/discordgo"
 	"github.com/gorilla/sessions"
@@ -19,13 +25,28 @@ import (
 
 	"pkg.fogo.sh/almanac/pkg/content"
 	"pkg.fogo.sh/almanac/pkg/content/extensions"
+	"pkg.fogo.sh/almanac/pkg/history"
 	"pkg.fogo.sh/almanac/pkg/static"
+	"pkg.fogo.sh/almanac/pkg/utils"
 )
 
 type Config struct {
 	Addr             string
 	ContentDir       string
 	UseBundledAssets bool
+	EnableEditing    bool
+	BaseURL          string
+	Dates            content.DateConfig
+	Sitemap          content.SitemapConfig
+	Contributors     []content.ContributorConfig
+	Categories       map[string]content.CategoryConfig
+	// ContentRemote is a git repository to clone into ContentDir and pull
+	// from, if its URL is set.
+	ContentRemote history.RemoteConfig
+	// WebhookSecret verifies push webhooks; they're rejected if it is empty.
+	WebhookSecret string
+	// IncludeDrafts shows drafts and unpublished pages to everyone.
+	IncludeDrafts bool
 
 	UseDiscordOAuth     bool
 	DiscordClientId     string
@@ -42,9 +63,23 @@ type Server struct {
 	config   Config
 	oauth    *oauth2.Config
 	parser   *content.Parser
+	// writeLock serialises writes to the content directory.
+	writeLock sync.Mutex
+	// contentLock is held for reading by requests, and for writing while
+	// pulling from the content remote.
+	contentLock sync.RWMutex
 }
 
 func (s *Server) Start() error {
+	if s.config.ContentRemote.URL != "" {
+		err := s.pullContent()
+		if err != nil {
+			return err
+		}
+
+		go s.pollContentRemote()
+	}
+
 	err := s.echoInst.Start(s.config.Addr)
 	if err != nil && !errors.Is(err, http.ErrServerClosed) {
 		return err
@@ -125,6 +160,11 @@ func (s *Server) httpError(err error, c echo.Context) {
 		message = he.Message.(string)
 	}
 
+	if strings.HasPrefix(c.Path(), "/api/") {
+		_ = c.JSON(code, map[string]string{"error": message})
+		return
+	}
+
 	_ = c.Render(code, "page", content.PageTemplateData{
 		Content: template.HTML(fmt.Sprintf("<p>%s</p>", message)),
 		Page: &content.Page{
@@ -133,29 +173,63 @@ func (s *Server) httpError(err error, c echo.Context) {
 	})
 }
 
+func (s *Server) isLoggedIn(c echo.Context) bool {
+	if !s.config.UseDiscordOAuth {
+		return true
+	}
+
+	sess := getSession(c)
+	loggedIn, ok := sess.Values["loggedIn"].(bool)
+	return ok && loggedIn
+}
+
+func serveNotLoggedIn(c echo.Context) error {
+	return c.Render(http.StatusOK, "page", content.PageTemplateData{
+		Content: "<p>You must be logged in to view this page - click <a href=\"/oauth/auth\">here</a> to log in.</p>",
+		Page: &content.Page{
+			Title: "Not Logged In",
+		},
+	})
+}
+
+func (s *Server) renderPage(c echo.Context, pages map[string]*content.Page, page *content.Page) error {
+	allPageTitles := s.parser.AllPageTitles(pages)
+
+	contributors, err := s.parser.PageContributors(page)
+	if err != nil {
+		slog.Warn("Failed to find page contributors", "page", page.Title, "error", err)
+	}
+
+	return c.Render(http.StatusOK, "page", content.PageTemplateData{
+		AllPageTitles: allPageTitles,
+		Content:       template.HTML(string(page.ParsedContent)),
+		Page:          page,
+		Dates:         s.parser.Dates,
+		Feeds:         content.FeedLinksFor(page),
+		ShowHistory:   page.Path != nil && page.HasHistory,
+		ShowEdit:      page.Path != nil && s.canEdit(c),
+		Contributors:  contributors,
+
+		CanPreviewDrafts: !s.parser.IncludeDrafts && s.canPreviewDrafts(c),
+		PreviewingDrafts: s.previewingDrafts(c),
+	})
+}
+
 func (s *Server) servePage(c echo.Context) error {
-	if s.config.UseDiscordOAuth {
-		sess := getSession(c)
-		loggedIn, ok := sess.Values["loggedIn"].(bool)
-		if !ok || !loggedIn {
-			return c.Render(http.StatusOK, "page", content.PageTemplateData{
-				Content: "<p>You must be logged in to view this page - click <a href=\"/oauth/auth\">here</a> to log in.</p>",
-				Page: &content.Page{
-					Title: "Not Logged In",
-				},
-			})
-		}
+	if !s.isLoggedIn(c) {
+		return serveNotLoggedIn(c)
 	}
 
 	pageKey := c.Param("page")
 
-	pages, err := s.parser.DiscoverPages(s.config.ContentDir)
+	pages, err := s.discoverPages(c)
 
 	if err != nil {
 		return fmt.Errorf("error discovering pages: %w", err)
 	}
 
 	var page *content.Page
+	var representation *content.Representation
 
 	if pageKey == "" {
 		page, err = s.parser.FindRootPage(pages)
@@ -165,22 +239,656 @@ func (s *Server) servePage(c echo.Context) error {
 		}
 	} else {
 		var ok bool
-		page, ok = pages[pageKey]
+		page, ok = s.parser.FindPage(pages, pageKey)
+
+		// Titles can contain dots, so a suffix is only treated as a
+		// representation if no page has the full title.
+		if !ok {
+			if dot := strings.LastIndex(pageKey, "."); dot != -1 {
+				if r, found := content.FindRepresentation(pageKey[dot+1:]); found {
+					page, ok = s.parser.FindPage(pages, pageKey[:dot])
+					representation = &r
+				}
+			}
+		}
+
+		if !ok && representation == nil && s.canEdit(c) && content.ValidNewPageTitle(pageKey) {
+			return s.serveCreate(c, pages, pageKey)
+		}
 
 		if !ok {
 			return serveNotFound(c)
 		}
 	}
 
-	allPageTitles := s.parser.AllPageTitles(pages)
+	if representation == nil {
+		c.Response().Header().Add(echo.HeaderVary, echo.HeaderAccept)
+		representation = negotiateRepresentation(c.Request().Header.Get(echo.HeaderAccept))
+	}
+
+	if representation != nil {
+		data, err := s.parser.Represent(page, *representation, s.baseURL(c))
+		if errors.Is(err, content.ErrNoSource) {
+			return serveNotFound(c)
+		} else if err != nil {
+			return fmt.Errorf("error creating %s representation: %w", representation.Extension, err)
+		}
+
+		return c.Blob(http.StatusOK, representation.ContentType, data)
+	}
+
+	return s.renderPage(c, pages, page)
+}
+
+var representationMediaTypes = map[string]string{
+	"text/markdown":    "md",
+	"text/plain":       "txt",
+	"application/json": "json",
+}
+
+// negotiateRepresentation picks the page representation preferred by an
+// Accept header, or nil if HTML is preferred or nothing else is acceptable.
+func negotiateRepresentation(accept string) *content.Representation {
+	bestType, bestQuality := "", 0.0
+
+	for _, mediaRange := range strings.Split(accept, ",") {
+		mediaType, params, _ := strings.Cut(mediaRange, ";")
+		mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+
+		if _, ok := representationMediaTypes[mediaType]; !ok && mediaType != echo.MIMETextHTML {
+			continue
+		}
+
+		quality := 1.0
+		for _, param := range strings.Split(params, ";") {
+			if value, ok := strings.CutPrefix(strings.TrimSpace(param), "q="); ok {
+				if q, err := strconv.ParseFloat(value, 64); err == nil {
+					quality = q
+				}
+			}
+		}
+
+		if quality > bestQuality {
+			bestType, bestQuality = mediaType, quality
+		}
+	}
+
+	extension, ok := representationMediaTypes[bestType]
+	if !ok {
+		return nil
+	}
+
+	representation, _ := content.FindRepresentation(extension)
+	return &representation
+}
+
+// pageHistory finds a content page and reads its history from the content
+// repository.
+func (s *Server) pageHistory(c echo.Context) (map[string]*content.Page, *content.Page, []history.Change, error) {
+	pages, err := s.discoverPages(c)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("error discovering pages: %w", err)
+	}
+
+	page, ok := pages[c.Param("page")]
+	if !ok || page.Path == nil {
+		return nil, nil, nil, echo.NewHTTPError(http.StatusNotFound, "Looks like this page doesn't exist yet")
+	}
+
+	repo, err := history.Open(s.config.ContentDir)
+	if errors.Is(err, history.ErrNotRepository) {
+		return nil, nil, nil, echo.NewHTTPError(http.StatusNotFound, "The content directory isn't a git repository")
+	}
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("error opening content repository: %w", err)
+	}
+
+	changes, err := repo.FileHistory(filepath.Base(*page.Path))
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("error reading page history: %w", err)
+	}
+
+	return pages, page, changes, nil
+}
+
+func (s *Server) serveHistory(c echo.Context) error {
+	if !s.isLoggedIn(c) {
+		return serveNotLoggedIn(c)
+	}
+
+	pages, page, changes, err := s.pageHistory(c)
+	if err != nil {
+		return err
+	}
+
+	historyPage, err := s.parser.HistoryPage(page, changes)
+	if err != nil {
+		return fmt.Errorf("error creating page: %w", err)
+	}
+
+	return s.renderPage(c, pages, historyPage)
+}
+
+func (s *Server) serveRevision(c echo.Context) error {
+	if !s.isLoggedIn(c) {
+		return serveNotLoggedIn(c)
+	}
+
+	pages, page, changes, err := s.pageHistory(c)
+	if err != nil {
+		return err
+	}
+
+	change, err := history.FindChange(changes, c.Param("revision"))
+	if errors.Is(err, history.ErrRevisionNotFound) {
+		return serveNotFound(c)
+	}
+
+	repo, err := history.Open(s.config.ContentDir)
+	if err != nil {
+		return fmt.Errorf("error opening content repository: %w", err)
+	}
+
+	source, err := repo.FileAt(change.Hash, change.Path)
+	if err != nil {
+		return fmt.Errorf("error reading revision: %w", err)
+	}
+
+	revision, err := s.parser.RevisionPage(page, change, source, content.PageIndexOf(pages))
+	if err != nil {
+		return fmt.Errorf("error creating page: %w", err)
+	}
 
 	return c.Render(http.StatusOK, "page", content.PageTemplateData{
-		AllPageTitles: allPageTitles,
+		AllPageTitles: s.parser.AllPageTitles(pages),
+		Content:       template.HTML(string(revision.ParsedContent)),
+		Page:          revision,
+		Dates:         s.parser.Dates,
+		Revision:      &change,
+	})
+}
+
+// diffSide reads the version of a page to compare from a revision, or from
+// disk if revision is empty.
+func (s *Server) diffSide(
+	repo *history.Repository,
+	page *content.Page,
+	changes []history.Change,
+	revision string,
+) (content.DiffSide, error) {
+	if revision == "" {
+		source, err := os.ReadFile(*page.Path)
+		if err != nil {
+			return content.DiffSide{}, fmt.Errorf("error reading page: %w", err)
+		}
+
+		return content.DiffSide{Label: "the current version", Link: page.Title, Source: source}, nil
+	}
+
+	change, err := history.FindChange(changes, revision)
+	if err != nil {
+		return content.DiffSide{}, echo.NewHTTPError(http.StatusNotFound, "Revision not found")
+	}
+
+	source, err := repo.FileAt(change.Hash, change.Path)
+	if err != nil {
+		return content.DiffSide{}, fmt.Errorf("error reading revision: %w", err)
+	}
+
+	return content.DiffSide{
+		Label:  fmt.Sprintf("revision %s", change.ShortHash()),
+		Link:   content.RevisionTitleFor(page.Title, change.Hash),
+		Source: source,
+	}, nil
+}
+
+// serveDiff compares two versions of a page, given by the from and to query
+// parameters. Without to, the current version is used. Without from, the
+// revision before to is used.
+func (s *Server) serveDiff(c echo.Context) error {
+	if !s.isLoggedIn(c) {
+		return serveNotLoggedIn(c)
+	}
+
+	pages, page, changes, err := s.pageHistory(c)
+	if err != nil {
+		return err
+	}
+
+	repo, err := history.Open(s.config.ContentDir)
+	if err != nil {
+		return fmt.Errorf("error opening content repository: %w", err)
+	}
+
+	to, err := s.diffSide(repo, page, changes, c.QueryParam("to"))
+	if err != nil {
+		return err
+	}
+
+	fromRevision := c.QueryParam("from")
+	if fromRevision == "" {
+		// Find the revision before to, which is the latest revision when
+		// comparing against the current version.
+		previous := 0
+		if c.QueryParam("to") != "" {
+			toChange, _ := history.FindChange(changes, c.QueryParam("to"))
+			for i, change := range changes {
+				if change.Hash == toChange.Hash {
+					previous = i + 1
+				}
+			}
+		}
+
+		if previous < len(changes) {
+			fromRevision = changes[previous].Hash
+		}
+	}
+
+	from := content.DiffSide{Label: "nothing"}
+	if fromRevision != "" {
+		from, err = s.diffSide(repo, page, changes, fromRevision)
+		if err != nil {
+			return err
+		}
+	}
+
+	diffPage, err := s.parser.DiffPage(page, from, to, content.PageIndexOf(pages))
+	if err != nil {
+		return fmt.Errorf("error creating page: %w", err)
+	}
+
+	return s.renderPage(c, pages, diffPage)
+}
+
+// canEdit reports whether the current user may edit pages. Editing must be
+// enabled, and the user must be logged in with Discord OAuth.
+func (s *Server) canEdit(c echo.Context) bool {
+	return s.config.EnableEditing && s.config.UseDiscordOAuth && s.isLoggedIn(c)
+}
+
+// serveCreate offers to create a missing page.
+func (s *Server) serveCreate(c echo.Context, pages map[string]*content.Page, title string) error {
+	page, err := s.parser.CreatePage(pages, title)
+	if err != nil {
+		return fmt.Errorf("error creating page: %w", err)
+	}
+
+	return c.Render(http.StatusNotFound, "page", content.PageTemplateData{
+		AllPageTitles: s.parser.AllPageTitles(pages),
+		Content:       template.HTML(string(page.ParsedContent)),
+		Page:          page,
+		Dates:         s.parser.Dates,
+	})
+}
+
+// editablePage finds the content page being edited, checking that the user
+// may edit it. If the page doesn't exist yet, it returns a page with the path
+// it will be created at.
+func (s *Server) editablePage(c echo.Context) (map[string]*content.Page, *content.Page, error) {
+	if !s.canEdit(c) {
+		return nil, nil, echo.NewHTTPError(http.StatusForbidden, "Editing is disabled")
+	}
+
+	pages, err := s.discoverPages(c)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error discovering pages: %w", err)
+	}
+
+	page, ok := pages[c.Param("page")]
+	if !ok {
+		if !content.ValidNewPageTitle(c.Param("page")) {
+			return nil, nil, echo.NewHTTPError(http.StatusNotFound, "Looks like this page doesn't exist yet")
+		}
+
+		path := filepath.Join(s.config.ContentDir, c.Param("page")+".md")
+
+		// Hidden pages are missing from pages, but their source must not be
+		// shown as if they were new pages.
+		if _, err := os.Stat(path); err == nil && !s.previewingDrafts(c) {
+			return nil, nil, echo.NewHTTPError(http.StatusNotFound, "Looks like this page doesn't exist yet")
+		}
+
+		page = &content.Page{Title: c.Param("page"), Path: &path}
+	}
+
+	if page.Path == nil {
+		return nil, nil, echo.NewHTTPError(http.StatusForbidden, "Special pages can't be edited")
+	}
+
+	return pages, page, nil
+}
+
+func (s *Server) renderEditPage(c echo.Context, pages map[string]*content.Page, status int, data content.EditData) error {
+	data.CSRFToken, _ = c.Get(middleware.DefaultCSRFConfig.ContextKey).(string)
+
+	page, err := content.EditPage(data)
+	if err != nil {
+		return fmt.Errorf("error creating page: %w", err)
+	}
+
+	return c.Render(status, "page", content.PageTemplateData{
+		AllPageTitles: s.parser.AllPageTitles(pages),
 		Content:       template.HTML(string(page.ParsedContent)),
 		Page:          page,
+		Dates:         s.parser.Dates,
 	})
 }
 
+func (s *Server) serveEdit(c echo.Context) error {
+	if !s.isLoggedIn(c) {
+		return serveNotLoggedIn(c)
+	}
+
+	pages, page, err := s.editablePage(c)
+	if err != nil {
+		return err
+	}
+
+	source, err := os.ReadFile(*page.Path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return s.renderEditPage(c, pages, http.StatusOK, content.EditData{
+			Title:  page.Title,
+			Source: s.parser.NewPageSource(c.QueryParam("category")),
+		})
+	} else if err != nil {
+		return fmt.Errorf("error reading page: %w", err)
+	}
+
+	return s.renderEditPage(c, pages, http.StatusOK, content.EditData{
+		Title:  page.Title,
+		Source: string(source),
+		Base:   content.SourceHash(source),
+	})
+}
+
+func (s *Server) saveEdit(c echo.Context) error {
+	if !s.isLoggedIn(c) {
+		return serveNotLoggedIn(c)
+	}
+
+	pages, page, err := s.editablePage(c)
+	if err != nil {
+		return err
+	}
+
+	source := []byte(strings.ReplaceAll(c.FormValue("content"), "\r\n", "\n"))
+	data := content.EditData{
+		Title:  page.Title,
+		Source: string(source),
+		Base:   c.FormValue("base"),
+	}
+
+	_, err = s.parser.ParsePage(*page.Path, source, nil)
+	if err != nil {
+		data.Error = fmt.Sprintf("The page couldn't be saved: %v", err)
+		return s.renderEditPage(c, pages, http.StatusBadRequest, data)
+	}
+
+	s.writeLock.Lock()
+	defer s.writeLock.Unlock()
+
+	// New pages have no base, and conflict with a page created in the meantime.
+	currentBase := ""
+	current, err := os.ReadFile(*page.Path)
+	if err == nil {
+		currentBase = content.SourceHash(current)
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("error reading page: %w", err)
+	}
+
+	if currentBase != data.Base {
+		data.Error = "The page was changed by someone else while you were editing it. " +
+			"Copy your changes, then reload the page to edit the latest version."
+		return s.renderEditPage(c, pages, http.StatusConflict, data)
+	}
+
+	err = utils.WriteFileAtomic(*page.Path, source, 0644)
+	if err != nil {
+		return fmt.Errorf("error saving page: %w", err)
+	}
+
+	if currentBase == "" {
+		slog.Info("Page created", "page", page.Title)
+	} else {
+		slog.Info("Page edited", "page", page.Title)
+	}
+
+	return c.Redirect(http.StatusSeeOther, "/"+url.PathEscape(page.Title))
+}
+
+// servePreview renders markdown, either the request body or the "content"
+// form field, into an HTML fragment as it would appear on a page. Links to
+// missing pages are marked as red links.
+func (s *Server) servePreview(c echo.Context) error {
+	if !s.isLoggedIn(c) {
+		return echo.NewHTTPError(http.StatusUnauthorized, "You must be logged in to preview pages")
+	}
+
+	var source []byte
+	if strings.HasPrefix(c.Request().Header.Get(echo.HeaderContentType), echo.MIMEApplicationForm) ||
+		strings.HasPrefix(c.Request().Header.Get(echo.HeaderContentType), echo.MIMEMultipartForm) {
+		source = []byte(c.FormValue("content"))
+	} else {
+		var err error
+		source, err = io.ReadAll(c.Request().Body)
+		if err != nil {
+			return fmt.Errorf("error reading request body: %w", err)
+		}
+	}
+	source = []byte(strings.ReplaceAll(string(source), "\r\n", "\n"))
+
+	title := c.QueryParam("title")
+	if title == "" {
+		title = "Preview"
+	}
+
+	pages, err := s.discoverPages(c)
+	if err != nil {
+		return fmt.Errorf("error discovering pages: %w", err)
+	}
+
+	page, err := s.parser.ParsePage(filepath.Join(s.config.ContentDir, title+".md"), source, content.PageIndexOf(pages))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	return c.HTMLBlob(http.StatusOK, page.ParsedContent)
+}
+
+func (s *Server) serveOnThisDay(c echo.Context) error {
+	if !s.isLoggedIn(c) {
+		return serveNotLoggedIn(c)
+	}
+
+	month, day, err := content.ParseMonthDay(c.Param("day"))
+	if err != nil {
+		return serveNotFound(c)
+	}
+
+	pages, err := s.discoverPages(c)
+	if err != nil {
+		return fmt.Errorf("error discovering pages: %w", err)
+	}
+
+	page, err := s.parser.OnThisDayPage(pages, content.OnThisDayTitleFor(month, day), month, day, s.parser.Now())
+	if err != nil {
+		return fmt.Errorf("error creating page: %w", err)
+	}
+
+	return s.renderPage(c, pages, page)
+}
+
+func (s *Server) serveAllPages(c echo.Context) error {
+	if !s.isLoggedIn(c) {
+		return serveNotLoggedIn(c)
+	}
+
+	number, err := strconv.Atoi(c.Param("number"))
+	if err != nil {
+		return serveNotFound(c)
+	}
+
+	// The first page of the index is served as $AllPages.
+	if number == 1 {
+		return c.Redirect(http.StatusMovedPermanently, "/"+content.AllPagesTitle)
+	}
+
+	pages, err := s.discoverPages(c)
+	if err != nil {
+		return fmt.Errorf("error discovering pages: %w", err)
+	}
+
+	page, err := s.parser.AllPagesPage(pages, number)
+	if err != nil {
+		return serveNotFound(c)
+	}
+
+	return s.renderPage(c, pages, page)
+}
+
+func (s *Server) baseURL(c echo.Context) string {
+	if s.config.BaseURL != "" {
+		return s.config.BaseURL
+	}
+
+	return fmt.Sprintf("%s://%s", c.Scheme(), c.Request().Host)
+}
+
+func (s *Server) serveRecentChanges(c echo.Context) error {
+	if !s.isLoggedIn(c) {
+		return serveNotLoggedIn(c)
+	}
+
+	filter := content.RecentChangesFilter{Category: c.QueryParam("category")}
+	if days := c.QueryParam("days"); days != "" {
+		var err error
+		filter.Days, err = strconv.Atoi(days)
+		if err != nil || filter.Days < 0 {
+			return echo.NewHTTPError(http.StatusBadRequest, "Invalid number of days")
+		}
+	}
+
+	pages, err := s.discoverPages(c)
+	if err != nil {
+		return fmt.Errorf("error discovering pages: %w", err)
+	}
+
+	page, err := s.parser.RecentChangesPage(pages, filter, true)
+	if err != nil {
+		return fmt.Errorf("error creating page: %w", err)
+	}
+
+	return s.renderPage(c, pages, page)
+}
+
+func (s *Server) serveRandom(c echo.Context) error {
+	if !s.isLoggedIn(c) {
+		return serveNotLoggedIn(c)
+	}
+
+	pages, err := s.discoverPages(c)
+	if err != nil {
+		return fmt.Errorf("error discovering pages: %w", err)
+	}
+
+	title, ok := s.parser.RandomPage(pages, c.QueryParam("category"))
+	if !ok {
+		return serveNotFound(c)
+	}
+
+	c.Response().Header().Set("Cache-Control", "no-store")
+	return c.Redirect(http.StatusFound, "/"+url.PathEscape(title))
+}
+
+func (s *Server) serveCalendar(c echo.Context) error {
+	if !s.isLoggedIn(c) {
+		return echo.NewHTTPError(http.StatusUnauthorized, "You must be logged in to view this calendar")
+	}
+
+	category := ""
+	if c.Param("category") != "" {
+		var ok bool
+		category, ok = strings.CutSuffix(c.Param("category"), ".ics")
+		if !ok {
+			return serveNotFound(c)
+		}
+	}
+
+	pages, err := s.discoverPages(c)
+	if err != nil {
+		return fmt.Errorf("error discovering pages: %w", err)
+	}
+
+	if category != "" {
+		if _, ok := s.parser.PagesByCategory(pages)[category]; !ok {
+			return serveNotFound(c)
+		}
+	}
+
+	return c.Blob(http.StatusOK, "text/calendar; charset=utf-8", s.parser.Calendar(pages, category, s.baseURL(c)))
+}
+
+func (s *Server) serveFeed(c echo.Context) error {
+	if !s.isLoggedIn(c) {
+		return echo.NewHTTPError(http.StatusUnauthorized, "You must be logged in to view this feed")
+	}
+
+	category, extension := "", c.Param("format")
+	if c.Param("category") != "" {
+		index := strings.LastIndex(c.Param("category"), ".")
+		if index == -1 {
+			return serveNotFound(c)
+		}
+		category, extension = c.Param("category")[:index], c.Param("category")[index+1:]
+	}
+
+	format, ok := content.FindFeedFormat(extension)
+	if !ok {
+		return serveNotFound(c)
+	}
+
+	pages, err := s.discoverPages(c)
+	if err != nil {
+		return fmt.Errorf("error discovering pages: %w", err)
+	}
+
+	if category != "" {
+		if _, ok := s.parser.PagesByCategory(pages)[category]; !ok {
+			return serveNotFound(c)
+		}
+	}
+
+	feed, err := s.parser.Feed(pages, category, format, s.baseURL(c))
+	if err != nil {
+		return fmt.Errorf("error creating feed: %w", err)
+	}
+
+	return c.Blob(http.StatusOK, format.ContentType+"; charset=utf-8", feed)
+}
+
+func (s *Server) serveSitemap(c echo.Context) error {
+	// Pages behind Discord OAuth aren't public, so there's nothing to advertise.
+	if s.config.UseDiscordOAuth {
+		return serveNotFound(c)
+	}
+
+	pages, err := s.discoverPages(c)
+	if err != nil {
+		return fmt.Errorf("error discovering pages: %w", err)
+	}
+
+	sitemap, err := s.parser.SitemapXML(pages, s.baseURL(c))
+	if err != nil {
+		return fmt.Errorf("error creating sitemap: %w", err)
+	}
+
+	return c.Blob(http.StatusOK, "application/xml; charset=utf-8", sitemap)
+}
+
+func (s *Server) serveRobots(c echo.Context) error {
+	return c.Blob(http.StatusOK, "text/plain; charset=utf-8", content.Robots(s.baseURL(c), !s.config.UseDiscordOAuth))
+}
+
 func NewServer(config Config) *Server {
 	slog.Debug(
 		"Creating server",
@@ -229,6 +937,15 @@ func NewServer(config Config) *Server {
 		}
 	}
 
+	if config.EnableEditing && !config.UseDiscordOAuth {
+		slog.Error("Editing requires Discord OAuth, otherwise anyone could edit pages")
+		os.Exit(1)
+	}
+
+	if config.EnableEditing && config.ContentRemote.URL != "" {
+		slog.Warn("Editing is enabled with a content remote, edits will not be pushed and will be overwritten by changes from the remote")
+	}
+
 	resolver, err := extensions.NewDiscordUserResolver(
 		extensions.DiscordUserResolverConfig{
 			DiscordToken: config.DiscordToken,
@@ -264,12 +981,55 @@ func NewServer(config Config) *Server {
 		echoInst: echoInst,
 		config:   config,
 		oauth:    oauthConfig,
-		parser:   &content.Parser{DiscordUserResolver: resolver},
+		parser: &content.Parser{
+			DiscordUserResolver: resolver,
+			Dates:               config.Dates,
+			Sitemap:             config.Sitemap,
+			Contributors:        config.Contributors,
+			Categories:          config.Categories,
+			IncludeDrafts:       config.IncludeDrafts,
+		},
 	}
 
 	echoInst.HTTPErrorHandler = server.httpError
 
+	echoInst.Use(server.readContent)
+
+	echoInst.GET("/sitemap.xml", server.serveSitemap)
+	echoInst.GET("/robots.txt", server.serveRobots)
+	echoInst.GET("/$OnThisDay/:day", server.serveOnThisDay)
+	echoInst.GET("/$RecentChanges", server.serveRecentChanges)
+	echoInst.GET("/$Random", server.serveRandom)
+	echoInst.GET("/$AllPages/:number", server.serveAllPages)
+	echoInst.POST(pushHookPath, server.servePushHook, middleware.BodyLimit("5M"))
+	echoInst.POST("/_almanac/preview", server.servePreview, middleware.BodyLimit("1M"))
+	echoInst.GET("/$Calendar.ics", server.serveCalendar)
+	echoInst.GET("/$Calendar/:category", server.serveCalendar)
+	echoInst.GET("/$Feed.:format", server.serveFeed)
+	echoInst.GET("/$Feed/:category", server.serveFeed)
+	api := echoInst.Group("/api/v1")
+	api.GET("/pages", server.serveAPIPages)
+	api.GET("/pages/:page", server.serveAPIPage)
+	api.GET("/pages/:page/source", server.serveAPIPageSource)
+	api.GET("/pages/:page/html", server.serveAPIPageHTML)
+	api.GET("/categories", server.serveAPICategories)
+	api.Any("/*", serveAPINotFound)
+
 	echoInst.GET("/:page", server.servePage)
+	echoInst.GET("/:page/history", server.serveHistory)
+	echoInst.GET("/:page/revisions/:revision", server.serveRevision)
+	echoInst.GET("/:page/diff", server.serveDiff)
+
+	csrf := middleware.CSRFWithConfig(middleware.CSRFConfig{
+		TokenLookup:    "form:_csrf",
+		CookiePath:     "/",
+		CookieHTTPOnly: true,
+		CookieSameSite: http.SameSiteStrictMode,
+	})
+	echoInst.GET("/:page/edit", server.serveEdit, csrf)
+	echoInst.POST("/:page/edit", server.saveEdit, csrf)
+	echoInst.GET(draftPreviewPath, server.serveDraftPreview, csrf)
+	echoInst.POST(draftPreviewPath, server.saveDraftPreview, csrf)
 	echoInst.GET("/", server.servePage)
 
 	echoInst.GET("/oauth/auth", server.oauthAuth)
@@ -0,0 +1,24 @@
+(async () => {
+  const status = document.getElementById("random-status");
+
+  try {
+    const response = await fetch("/$Random/index.json");
+    const index = await response.json();
+
+    const category = new URLSearchParams(window.location.search).get("category");
+    const candidates = category
+      ? index.categories[category] || []
+      : index.pages.map((_, i) => i);
+
+    if (candidates.length === 0) {
+      status.textContent = "There are no pages to pick from.";
+      return;
+    }
+
+    const title = index.pages[candidates[Math.floor(Math.random() * candidates.length)]];
+    window.location.replace("/" + encodeURIComponent(title));
+  } catch (err) {
+    status.textContent = "Couldn't pick a random page.";
+    console.error(err);
+  }
+})();